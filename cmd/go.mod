@@ -0,0 +1,12 @@
+module github.com/euforic/pkg-go/cmd
+
+go 1.22.5
+
+require (
+	github.com/euforic/pkg-go/jwt v0.0.0-20261019184131-0faa5eb8e936
+	github.com/euforic/pkg-go/rsa v0.0.0-20261019184131-0faa5eb8e936
+	github.com/golang-jwt/jwt v3.2.2+incompatible
+	golang.org/x/crypto v0.22.0
+)
+
+require golang.org/x/sys v0.19.0 // indirect
@@ -0,0 +1,274 @@
+// Command jwt decodes, verifies and mints JSON Web Tokens locally so that
+// production tokens never have to be pasted into third party websites.
+//
+// Usage:
+//
+//	jwt decode [token]
+//	jwt verify (-key public.pem | -jwks jwks.json) [token]
+//	jwt sign -key private.pem -claims claims.json [-ttl 1h] [-kid id]
+//
+// When the token argument is omitted or is "-" it is read from stdin.
+package main
+
+import (
+	"bytes"
+	cryptorsa "crypto/rsa"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/euforic/pkg-go/jwt"
+	"github.com/euforic/pkg-go/rsa"
+	jwtgo "github.com/golang-jwt/jwt"
+)
+
+var (
+	// ErrUsage is returned when the command line is invalid
+	ErrUsage = errors.New("usage: jwt <decode|verify|sign> [flags] [token]")
+	// ErrMissingFlag is returned when a required flag is not set
+	ErrMissingFlag = errors.New("missing required flag")
+)
+
+// timeClaims are the registered claims holding NumericDate values
+var timeClaims = []string{"iat", "nbf", "exp"} //nolint:gochecknoglobals
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run executes the sub command named by the first argument
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "decode":
+		return decode(args[1:], stdin, stdout)
+	case "verify":
+		return verify(args[1:], stdin, stdout)
+	case "sign":
+		return sign(args[1:], stdout)
+	default:
+		return fmt.Errorf("unknown command %q: %w", args[0], ErrUsage)
+	}
+}
+
+// decode prints the header and claims of a token without verifying it
+func decode(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("decode", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	raw, err := readToken(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+
+	token, err := parseUnverified(raw)
+	if err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	return printToken(stdout, token)
+}
+
+// verify checks the signature and claims of a token against a public key
+func verify(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	keyFile := fs.String("key", "", "PEM encoded RSA public key file")
+	jwksFile := fs.String("jwks", "", "JSON Web Key Set file")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+
+	raw, err := readToken(fs.Arg(0), stdin)
+	if err != nil {
+		return err
+	}
+
+	var publicKey *cryptorsa.PublicKey
+	switch {
+	case *keyFile != "":
+		b, err := os.ReadFile(*keyFile)
+		if err != nil {
+			return fmt.Errorf("verify: read key: %w", err)
+		}
+
+		if publicKey, err = rsa.PublicKeyFromBytes(b); err != nil {
+			return fmt.Errorf("verify: %w", err)
+		}
+	case *jwksFile != "":
+		if publicKey, err = jwksKey(*jwksFile, raw); err != nil {
+			return fmt.Errorf("verify: %w", err)
+		}
+	default:
+		return fmt.Errorf("verify: -key or -jwks: %w", ErrMissingFlag)
+	}
+
+	token, err := jwt.New(nil, publicKey).Parse(raw, true)
+	if err != nil {
+		return fmt.Errorf("verify: %w", err)
+	}
+
+	fmt.Fprintln(stdout, "Signature verified")
+
+	return printToken(stdout, token)
+}
+
+// sign mints a new token from a claims file and a private key
+func sign(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("sign", flag.ContinueOnError)
+	keyFile := fs.String("key", "", "PEM encoded RSA private key file")
+	claimsFile := fs.String("claims", "", "JSON file holding the token claims")
+	ttl := fs.Duration("ttl", time.Hour, "time until the token expires")
+	kid := fs.String("kid", "", "key id to set in the token header")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("sign: %w", err)
+	}
+
+	if *keyFile == "" || *claimsFile == "" {
+		return fmt.Errorf("sign: -key and -claims: %w", ErrMissingFlag)
+	}
+
+	keyBytes, err := os.ReadFile(*keyFile)
+	if err != nil {
+		return fmt.Errorf("sign: read key: %w", err)
+	}
+
+	r := rsa.New()
+	if err := r.ReadPrivateKey(bytes.NewReader(keyBytes)); err != nil {
+		return fmt.Errorf("sign: %w", err)
+	}
+
+	claimsBytes, err := os.ReadFile(*claimsFile)
+	if err != nil {
+		return fmt.Errorf("sign: read claims: %w", err)
+	}
+
+	claims := jwtgo.MapClaims{}
+	if err := json.Unmarshal(claimsBytes, &claims); err != nil {
+		return fmt.Errorf("sign: parse claims: %w", err)
+	}
+
+	j := jwt.New(r.Private, r.Public)
+
+	token, err := j.Create(*ttl, claims)
+	if err != nil {
+		return fmt.Errorf("sign: %w", err)
+	}
+
+	if *kid != "" {
+		token.Header["kid"] = *kid
+	}
+
+	tokenStr, err := j.Sign(token)
+	if err != nil {
+		return fmt.Errorf("sign: %w", err)
+	}
+
+	fmt.Fprintln(stdout, tokenStr)
+
+	return nil
+}
+
+// jwksKey selects the key matching the token kid from a JWKS file
+func jwksKey(path, raw string) (*cryptorsa.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read jwks: %w", err)
+	}
+
+	set, err := jwt.ParseJWKS(b)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	token, err := parseUnverified(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	kid, _ := token.Header["kid"].(string)
+
+	return set.Key(kid) //nolint:wrapcheck
+}
+
+// parseUnverified decodes the header and claims of a token of any alg without
+// checking its signature
+func parseUnverified(raw string) (*jwt.Token, error) {
+	token, _, err := new(jwtgo.Parser).ParseUnverified(raw, jwtgo.MapClaims{})
+
+	// Tokens with an alg unknown to golang-jwt, such as ES256K, are still decoded
+	var ve *jwtgo.ValidationError
+	if err != nil && (!errors.As(err, &ve) || ve.Errors != jwtgo.ValidationErrorUnverifiable) {
+		return nil, fmt.Errorf("parse token: %w", err)
+	}
+
+	return jwt.NewToken(token), nil
+}
+
+// readToken returns the token argument or reads it from stdin
+func readToken(arg string, stdin io.Reader) (string, error) {
+	if arg != "" && arg != "-" {
+		return strings.TrimSpace(arg), nil
+	}
+
+	b, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", fmt.Errorf("read token: %w", err)
+	}
+
+	return strings.TrimSpace(string(b)), nil
+}
+
+// printToken writes the header, claims and time claims of a token
+func printToken(w io.Writer, token *jwt.Token) error {
+	claims, _ := token.Claims()
+
+	for _, part := range []struct {
+		name  string
+		value any
+	}{
+		{name: "Header", value: token.Header},
+		{name: "Claims", value: claims},
+	} {
+		b, err := json.MarshalIndent(part.value, "", "  ")
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", strings.ToLower(part.name), err)
+		}
+
+		fmt.Fprintf(w, "%s:\n%s\n", part.name, b)
+	}
+
+	now := time.Now()
+	for _, key := range timeClaims {
+		seconds, ok := claims[key].(float64)
+		if !ok {
+			continue
+		}
+
+		at := time.Unix(int64(seconds), 0).UTC()
+		rel := "from now"
+		if at.Before(now) {
+			rel = "ago"
+		}
+
+		fmt.Fprintf(w, "%s: %s (%s %s)\n", key, at.Format(time.RFC3339), now.Sub(at).Abs().Round(time.Second), rel)
+	}
+
+	if exp, ok := claims["exp"].(float64); ok && time.Unix(int64(exp), 0).Before(now) {
+		fmt.Fprintln(w, "Token is expired")
+	}
+
+	return nil
+}
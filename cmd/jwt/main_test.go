@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/euforic/pkg-go/jwt"
+	"github.com/euforic/pkg-go/rsa"
+	jwtgo "github.com/golang-jwt/jwt"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+
+	r := rsa.New()
+	if err := r.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var private, public bytes.Buffer
+	if err := r.WritePrivateKey(&private); err != nil {
+		t.Fatalf("WritePrivateKey() error = %v", err)
+	}
+	if err := r.WritePublicKey(&public); err != nil {
+		t.Fatalf("WritePublicKey() error = %v", err)
+	}
+
+	jwks, err := json.Marshal(jwt.JWKS{Keys: []jwt.JWK{jwt.NewJWK("test", r.Public)}})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	files := map[string][]byte{
+		"private.pem": private.Bytes(),
+		"public.pem":  public.Bytes(),
+		"jwks.json":   jwks,
+		"claims.json": []byte(`{"sub":"1234567890","admin":true}`),
+	}
+	for name, b := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), b, 0o600); err != nil {
+			t.Fatalf("WriteFile(%s) error = %v", name, err)
+		}
+	}
+
+	var out bytes.Buffer
+	signArgs := []string{"sign", "-key", filepath.Join(dir, "private.pem"), "-claims", filepath.Join(dir, "claims.json"), "-kid", "test"}
+	if err := run(signArgs, nil, &out); err != nil {
+		t.Fatalf("run(sign) error = %v", err)
+	}
+	token := strings.TrimSpace(out.String())
+
+	hmac, err := jwtgo.NewWithClaims(jwtgo.SigningMethodHS256, jwtgo.MapClaims{"sub": "hmac"}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	es256k := jwtgo.EncodeSegment([]byte(`{"alg":"ES256K","typ":"JWT"}`)) + "." + jwtgo.EncodeSegment([]byte(`{"sub":"wallet"}`)) + "." + jwtgo.EncodeSegment(make([]byte, 64))
+
+	tests := []struct {
+		name    string
+		args    []string
+		stdin   string
+		wantOut string
+		wantErr bool
+	}{
+		{name: "Decode argument", args: []string{"decode", token}, wantOut: `"sub": "1234567890"`},
+		{name: "Decode stdin", args: []string{"decode"}, stdin: token + "\n", wantOut: `"kid": "test"`},
+		{name: "Decode HS256", args: []string{"decode", hmac}, wantOut: `"alg": "HS256"`},
+		{name: "Decode unregistered alg", args: []string{"decode", es256k}, wantOut: `"sub": "wallet"`},
+		{name: "Decode malformed", args: []string{"decode", "not.a.token"}, wantErr: true},
+		{name: "Verify public key", args: []string{"verify", "-key", filepath.Join(dir, "public.pem"), token}, wantOut: "Signature verified"},
+		{name: "Verify jwks", args: []string{"verify", "-jwks", filepath.Join(dir, "jwks.json"), token}, wantOut: "Signature verified"},
+		{name: "Verify tampered", args: []string{"verify", "-key", filepath.Join(dir, "public.pem"), token + "x"}, wantErr: true},
+		{name: "Verify missing key", args: []string{"verify", token}, wantErr: true},
+		{name: "Unknown command", args: []string{"inspect"}, wantErr: true},
+		{name: "No command", args: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := run(tt.args, strings.NewReader(tt.stdin), &out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if !strings.Contains(out.String(), tt.wantOut) {
+				t.Errorf("run(%v) output = %q, want to contain %q", tt.args, out.String(), tt.wantOut)
+			}
+		})
+	}
+}
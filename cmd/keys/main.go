@@ -0,0 +1,574 @@
+// Command keys generates, converts, inspects and encrypts private and public
+// keys without writing one-off programs against the rsa package.
+//
+// Usage:
+//
+//	keys generate [-type rsa|ecdsa|ed25519] [-bits 2048] [-curve P-256] [-format pkcs1|pkcs8|jwk|ssh] [-encrypt] [-out file] [-pub file]
+//	keys convert -format pkcs1|pkcs8|jwk|ssh [-public] [-out file] [file]
+//	keys inspect [file]
+//	keys encrypt [-format pkcs8|ssh] [-new-passphrase-file file] [-out file] [file]
+//	keys decrypt [-format pkcs1|pkcs8|jwk|ssh] [-out file] [file]
+//
+// Keys are read from PEM (PKCS#1, PKCS#8, SEC 1, encrypted PKCS#8, OpenSSH,
+// PKIX or certificates), JWK or authorized_keys input. When the file argument
+// is omitted or is "-" the key is read from stdin. Passphrases are read from the
+// file named by -passphrase-file or from the KEYS_PASSPHRASE environment
+// variable, never from the command line. encrypt takes the passphrase of the
+// output from -new-passphrase-file or KEYS_NEW_PASSPHRASE when set, so that
+// the passphrase of an encrypted key can be changed. Only pkcs8 and ssh
+// private keys can be encrypted. Output files are written atomically and are
+// readable only by the owner.
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	cryptorsa "crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/euforic/pkg-go/rsa"
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	// ErrUsage is returned when the command line is invalid
+	ErrUsage = errors.New("usage: keys <generate|convert|inspect|encrypt|decrypt> [flags] [file]")
+	// ErrMissingFlag is returned when a required flag is not set
+	ErrMissingFlag = errors.New("missing required flag")
+	// ErrMissingPassphrase is returned when a passphrase is needed but none was given
+	ErrMissingPassphrase = errors.New("missing passphrase, set -passphrase-file or " + passphraseEnv)
+	// ErrUnsupportedKey is returned when a key type cannot be used with the requested format
+	ErrUnsupportedKey = errors.New("unsupported key type")
+)
+
+const (
+	// passphraseEnv is the environment variable read when -passphrase-file is not set
+	passphraseEnv = "KEYS_PASSPHRASE"
+	// newPassphraseEnv is the environment variable read when -new-passphrase-file is not set
+	newPassphraseEnv = "KEYS_NEW_PASSPHRASE"
+	// formatPKCS1 is PKCS#1 "RSA PRIVATE KEY" and "RSA PUBLIC KEY" PEM
+	formatPKCS1 = "pkcs1"
+	// formatPKCS8 is PKCS#8 "PRIVATE KEY" and PKIX "PUBLIC KEY" PEM
+	formatPKCS8 = "pkcs8"
+	// formatJWK is a JSON Web Key
+	formatJWK = "jwk"
+	// formatSSH is an "OPENSSH PRIVATE KEY" block or an authorized_keys line
+	formatSSH = "ssh"
+)
+
+// curves are the ECDSA curves accepted by generate, by name
+var curves = map[string]elliptic.Curve{ //nolint:gochecknoglobals
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run executes the sub command named by the first argument
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "generate":
+		return generate(args[1:], stdout)
+	case "convert":
+		return convert(args[1:], stdin, stdout)
+	case "inspect":
+		return inspect(args[1:], stdin, stdout)
+	case "encrypt":
+		return encrypt(args[1:], stdin, stdout)
+	case "decrypt":
+		return decrypt(args[1:], stdin, stdout)
+	default:
+		return fmt.Errorf("unknown command %q: %w", args[0], ErrUsage)
+	}
+}
+
+// generate creates a new key pair
+func generate(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	keyType := fs.String("type", "rsa", "key type: rsa, ecdsa or ed25519")
+	bits := fs.Int("bits", rsa.DefaultKeySize, "RSA key size in bits")
+	curve := fs.String("curve", "P-256", "ECDSA curve: P-256, P-384 or P-521")
+	format := fs.String("format", formatPKCS8, "output format: pkcs1, pkcs8, jwk or ssh")
+	encrypted := fs.Bool("encrypt", false, "encrypt the private key with the passphrase")
+	out := fs.String("out", "", "private key file, stdout when empty")
+	pubOut := fs.String("pub", "", "public key file written in the same format")
+	passphraseFile := fs.String("passphrase-file", "", "file holding the passphrase")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("generate: %w", err)
+	}
+
+	var key crypto.Signer
+	switch *keyType {
+	case "rsa":
+		r := rsa.New()
+		if err := r.GenerateWithOpts(rsa.WithKeySize(*bits)); err != nil {
+			return fmt.Errorf("generate: %w", err)
+		}
+		key = r.Private
+	case "ecdsa":
+		c, ok := curves[*curve]
+		if !ok {
+			return fmt.Errorf("generate: curve %q: %w", *curve, ErrUnsupportedKey)
+		}
+
+		e := rsa.NewEcdsa(c)
+		if err := e.Generate(); err != nil {
+			return fmt.Errorf("generate: %w", err)
+		}
+		key = e.Private
+	case "ed25519":
+		e := rsa.NewEd25519()
+		if err := e.Generate(); err != nil {
+			return fmt.Errorf("generate: %w", err)
+		}
+		key = e.Private
+	default:
+		return fmt.Errorf("generate: type %q: %w", *keyType, ErrUnsupportedKey)
+	}
+
+	var b []byte
+	var err error
+	if *encrypted {
+		var passphrase []byte
+		if passphrase, err = readPassphrase(*passphraseFile, passphraseEnv); err == nil {
+			b, err = encryptKey(key, *format, passphrase)
+		}
+	} else {
+		b, err = encodePrivateKey(key, *format)
+	}
+	if err != nil {
+		return fmt.Errorf("generate: %w", err)
+	}
+
+	if *pubOut != "" {
+		pub, err := encodePublicKey(key.Public(), *format)
+		if err != nil {
+			return fmt.Errorf("generate: %w", err)
+		}
+
+		if err := writeOutput(*pubOut, stdout, pub); err != nil {
+			return fmt.Errorf("generate: %w", err)
+		}
+	}
+
+	if err := writeOutput(*out, stdout, b); err != nil {
+		return fmt.Errorf("generate: %w", err)
+	}
+
+	return nil
+}
+
+// convert re-encodes a key in another format
+func convert(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	format := fs.String("format", "", "output format: pkcs1, pkcs8, jwk or ssh")
+	public := fs.Bool("public", false, "write only the public key")
+	out := fs.String("out", "", "output file, stdout when empty")
+	passphraseFile := fs.String("passphrase-file", "", "file holding the passphrase of encrypted keys")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("convert: %w", err)
+	}
+
+	if *format == "" {
+		return fmt.Errorf("convert: -format: %w", ErrMissingFlag)
+	}
+
+	private, pub, err := loadKey(fs.Arg(0), stdin, *passphraseFile)
+	if err != nil {
+		return fmt.Errorf("convert: %w", err)
+	}
+
+	var b []byte
+	if *public || private == nil {
+		b, err = encodePublicKey(pub, *format)
+	} else {
+		b, err = encodePrivateKey(private, *format)
+	}
+	if err != nil {
+		return fmt.Errorf("convert: %w", err)
+	}
+
+	if err := writeOutput(*out, stdout, b); err != nil {
+		return fmt.Errorf("convert: %w", err)
+	}
+
+	return nil
+}
+
+// inspect prints the type, size and fingerprints of a key
+func inspect(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	passphraseFile := fs.String("passphrase-file", "", "file holding the passphrase of encrypted keys")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("inspect: %w", err)
+	}
+
+	private, pub, err := loadKey(fs.Arg(0), stdin, *passphraseFile)
+	if err != nil {
+		return fmt.Errorf("inspect: %w", err)
+	}
+
+	keyType, bits, err := describe(pub)
+	if err != nil {
+		return fmt.Errorf("inspect: %w", err)
+	}
+
+	fingerprint, err := rsa.AnyPublicKeyFingerprint(pub)
+	if err != nil {
+		return fmt.Errorf("inspect: %w", err)
+	}
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return fmt.Errorf("inspect: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "Type: %s\n", keyType)
+	fmt.Fprintf(stdout, "Size: %d bits\n", bits)
+	fmt.Fprintf(stdout, "Private: %t\n", private != nil)
+	fmt.Fprintf(stdout, "Fingerprint: %s\n", fingerprint)
+	fmt.Fprintf(stdout, "SSH fingerprint: %s\n", ssh.FingerprintSHA256(sshPub))
+
+	return nil
+}
+
+// encrypt writes a private key as encrypted PKCS#8 or OpenSSH, re-encrypting
+// encrypted keys with a new passphrase when one is given
+func encrypt(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("encrypt", flag.ContinueOnError)
+	format := fs.String("format", formatPKCS8, "output format: pkcs8 or ssh")
+	out := fs.String("out", "", "output file, stdout when empty")
+	passphraseFile := fs.String("passphrase-file", "", "file holding the passphrase")
+	newPassphraseFile := fs.String("new-passphrase-file", "", "file holding the passphrase of the output, -passphrase-file when empty")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("encrypt: %w", err)
+	}
+
+	private, _, err := loadKey(fs.Arg(0), stdin, *passphraseFile)
+	if err != nil {
+		return fmt.Errorf("encrypt: %w", err)
+	}
+
+	if private == nil {
+		return fmt.Errorf("encrypt: %w", rsa.ErrNoPrivateKey)
+	}
+
+	passphrase, err := readPassphrase(*newPassphraseFile, newPassphraseEnv)
+	if err == nil && passphrase == nil {
+		passphrase, err = readPassphrase(*passphraseFile, passphraseEnv)
+	}
+	if err != nil {
+		return fmt.Errorf("encrypt: %w", err)
+	}
+
+	b, err := encryptKey(private, *format, passphrase)
+	if err != nil {
+		return fmt.Errorf("encrypt: %w", err)
+	}
+
+	if err := writeOutput(*out, stdout, b); err != nil {
+		return fmt.Errorf("encrypt: %w", err)
+	}
+
+	return nil
+}
+
+// decrypt writes an encrypted private key unencrypted
+func decrypt(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("decrypt", flag.ContinueOnError)
+	format := fs.String("format", formatPKCS8, "output format: pkcs1, pkcs8, jwk or ssh")
+	out := fs.String("out", "", "output file, stdout when empty")
+	passphraseFile := fs.String("passphrase-file", "", "file holding the passphrase")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("decrypt: %w", err)
+	}
+
+	private, _, err := loadKey(fs.Arg(0), stdin, *passphraseFile)
+	if err != nil {
+		return fmt.Errorf("decrypt: %w", err)
+	}
+
+	if private == nil {
+		return fmt.Errorf("decrypt: %w", rsa.ErrNoPrivateKey)
+	}
+
+	b, err := encodePrivateKey(private, *format)
+	if err != nil {
+		return fmt.Errorf("decrypt: %w", err)
+	}
+
+	if err := writeOutput(*out, stdout, b); err != nil {
+		return fmt.Errorf("decrypt: %w", err)
+	}
+
+	return nil
+}
+
+// loadKey reads a private or public key in any supported encoding. The
+// private key is nil when the input only holds a public key.
+func loadKey(arg string, stdin io.Reader, passphraseFile string) (crypto.Signer, crypto.PublicKey, error) { //nolint:ireturn
+	b, err := readInput(arg, stdin)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	trimmed := bytes.TrimSpace(b)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return loadJWK(trimmed)
+	case !bytes.HasPrefix(trimmed, []byte("-----")):
+		sshPub, _, _, _, err := ssh.ParseAuthorizedKey(trimmed)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parse key: %w", rsa.ErrFailedToParse)
+		}
+
+		cryptoPub, ok := sshPub.(ssh.CryptoPublicKey)
+		if !ok {
+			return nil, nil, fmt.Errorf("ssh key %s: %w", sshPub.Type(), ErrUnsupportedKey)
+		}
+
+		return nil, cryptoPub.CryptoPublicKey(), nil
+	}
+
+	block, _ := pem.Decode(trimmed)
+	if block == nil || !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+		pub, err := rsa.AnyPublicKeyFromBytes(trimmed)
+		if err != nil {
+			return nil, nil, err //nolint:wrapcheck
+		}
+
+		return nil, pub, nil
+	}
+
+	passphrase, err := readPassphrase(passphraseFile, passphraseEnv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	private, err := rsa.SignerFromBytes(trimmed, passphrase)
+	if errors.Is(err, rsa.ErrEncryptedKey) {
+		return nil, nil, ErrMissingPassphrase
+	}
+	if err != nil {
+		return nil, nil, err //nolint:wrapcheck
+	}
+
+	return private, private.Public(), nil
+}
+
+// loadJWK decodes an RSA JSON Web Key, which is private when it holds the private exponent
+func loadJWK(b []byte) (crypto.Signer, crypto.PublicKey, error) { //nolint:ireturn
+	var k rsa.JWK
+	if err := json.Unmarshal(b, &k); err != nil {
+		return nil, nil, fmt.Errorf("parse jwk: %w", err)
+	}
+
+	if k.D == "" {
+		pub, err := k.PublicKey()
+		if err != nil {
+			return nil, nil, err //nolint:wrapcheck
+		}
+
+		return nil, pub, nil
+	}
+
+	private, err := k.PrivateKey()
+	if err != nil {
+		return nil, nil, err //nolint:wrapcheck
+	}
+
+	return private, &private.PublicKey, nil
+}
+
+// encodePrivateKey encodes a private key in the format
+func encodePrivateKey(key crypto.Signer, format string) ([]byte, error) {
+	switch format {
+	case formatPKCS1, formatJWK:
+		private, ok := key.(*cryptorsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%s needs an RSA key, got %T: %w", format, key, ErrUnsupportedKey)
+		}
+
+		if format == formatPKCS1 {
+			return rsa.Rsa{Private: private}.EncodePrivateKey(rsa.FormatPEM) //nolint:wrapcheck
+		}
+
+		b, err := rsa.Rsa{Private: private}.EncodePrivateKey(rsa.FormatJWK)
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+
+		return append(b, '\n'), nil
+	case formatPKCS8:
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("marshal private key: %w", err)
+		}
+
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+	case formatSSH:
+		block, err := ssh.MarshalPrivateKey(key, "")
+		if err != nil {
+			return nil, fmt.Errorf("marshal ssh private key: %w", err)
+		}
+
+		return pem.EncodeToMemory(block), nil
+	default:
+		return nil, fmt.Errorf("format %q: %w", format, rsa.ErrUnsupportedFormat)
+	}
+}
+
+// encodePublicKey encodes a public key in the format
+func encodePublicKey(pub crypto.PublicKey, format string) ([]byte, error) {
+	switch format {
+	case formatPKCS1, formatJWK:
+		key, ok := pub.(*cryptorsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%s needs an RSA key, got %T: %w", format, pub, ErrUnsupportedKey)
+		}
+
+		if format == formatPKCS1 {
+			return pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(key)}), nil
+		}
+
+		b, err := rsa.Rsa{Public: key}.EncodePublicKey(rsa.FormatJWK)
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+
+		return append(b, '\n'), nil
+	case formatPKCS8:
+		der, err := x509.MarshalPKIXPublicKey(pub)
+		if err != nil {
+			return nil, fmt.Errorf("marshal public key: %w", err)
+		}
+
+		return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+	case formatSSH:
+		sshPub, err := ssh.NewPublicKey(pub)
+		if err != nil {
+			return nil, fmt.Errorf("marshal ssh public key: %w", err)
+		}
+
+		return ssh.MarshalAuthorizedKey(sshPub), nil
+	default:
+		return nil, fmt.Errorf("format %q: %w", format, rsa.ErrUnsupportedFormat)
+	}
+}
+
+// encryptKey encodes a private key as encrypted PKCS#8 or OpenSSH with the passphrase
+func encryptKey(key crypto.Signer, format string, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, ErrMissingPassphrase
+	}
+
+	switch format {
+	case formatPKCS8:
+		var buf bytes.Buffer
+		if err := rsa.WriteEncryptedKey(&buf, key, passphrase); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+
+		return buf.Bytes(), nil
+	case formatSSH:
+		block, err := ssh.MarshalPrivateKeyWithPassphrase(key, "", passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("marshal ssh private key: %w", err)
+		}
+
+		return pem.EncodeToMemory(block), nil
+	default:
+		return nil, fmt.Errorf("encrypted format %q, want pkcs8 or ssh: %w", format, rsa.ErrUnsupportedFormat)
+	}
+}
+
+// describe returns the type and size of a public key
+func describe(pub crypto.PublicKey) (string, int, error) {
+	switch key := pub.(type) {
+	case *cryptorsa.PublicKey:
+		return "RSA", key.N.BitLen(), nil
+	case *ecdsa.PublicKey:
+		return "ECDSA " + key.Curve.Params().Name, key.Curve.Params().BitSize, nil
+	case ed25519.PublicKey:
+		return "Ed25519", ed25519.PublicKeySize * 8, nil
+	default:
+		return "", 0, fmt.Errorf("%T: %w", pub, ErrUnsupportedKey)
+	}
+}
+
+// readPassphrase reads the passphrase from the file, or from the environment
+// variable when no file is set. It returns nil when neither is set.
+func readPassphrase(path, envName string) ([]byte, error) {
+	if path == "" {
+		if env, ok := os.LookupEnv(envName); ok {
+			return []byte(env), nil
+		}
+
+		return nil, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read passphrase: %w", err)
+	}
+
+	return bytes.TrimRight(b, "\r\n"), nil
+}
+
+// readInput returns the contents of the file argument or reads stdin
+func readInput(arg string, stdin io.Reader) ([]byte, error) {
+	if arg != "" && arg != "-" {
+		b, err := os.ReadFile(arg)
+		if err != nil {
+			return nil, fmt.Errorf("read key: %w", err)
+		}
+
+		return b, nil
+	}
+
+	b, err := io.ReadAll(stdin)
+	if err != nil {
+		return nil, fmt.Errorf("read key: %w", err)
+	}
+
+	return b, nil
+}
+
+// writeOutput atomically writes to the file, readable only by the owner, or
+// to stdout when path is empty
+func writeOutput(path string, stdout io.Writer, b []byte) error {
+	if path == "" {
+		if _, err := stdout.Write(b); err != nil {
+			return fmt.Errorf("write output: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := rsa.WriteKeyFile(path, b); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+
+	return nil
+}
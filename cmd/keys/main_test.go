@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/euforic/pkg-go/rsa"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	if err := os.WriteFile(path("passphrase"), []byte("secret\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(path("wrong"), []byte("wrong\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(path("new"), []byte("rotated\n"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	// An existing file with looser permissions is replaced by a 0600 key
+	if err := os.WriteFile(path("rsa.enc"), nil, 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	setup := [][]string{
+		{"generate", "-bits", "2048", "-format", "pkcs1", "-out", path("rsa.pem"), "-pub", path("rsa.pub")},
+		{"generate", "-type", "ecdsa", "-curve", "P-384", "-out", path("ec.pem")},
+		{"generate", "-type", "ed25519", "-format", "ssh", "-out", path("ed.key"), "-pub", path("ed.pub")},
+		{"encrypt", "-passphrase-file", path("passphrase"), "-out", path("rsa.enc"), path("rsa.pem")},
+		{"encrypt", "-passphrase-file", path("passphrase"), "-new-passphrase-file", path("new"), "-out", path("rsa.rot"), path("rsa.enc")},
+		{"generate", "-type", "ed25519", "-format", "ssh", "-encrypt", "-passphrase-file", path("passphrase"), "-out", path("ed.enc")},
+		{"convert", "-format", "jwk", "-out", path("rsa.jwk"), path("rsa.pem")},
+	}
+	for _, args := range setup {
+		var out bytes.Buffer
+		if err := run(args, nil, &out); err != nil {
+			t.Fatalf("run(%v) error = %v", args, err)
+		}
+	}
+
+	r := rsa.New()
+	b, err := os.ReadFile(path("rsa.pem"))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if err := r.ReadPrivateKey(bytes.NewReader(b)); err != nil {
+		t.Fatalf("ReadPrivateKey() error = %v", err)
+	}
+	fingerprint, _ := rsa.PublicKeyFingerprint(r.Public)
+
+	info, err := os.Stat(path("rsa.enc"))
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0o600 {
+		t.Errorf("rsa.enc mode = %v, want %v", mode, os.FileMode(0o600))
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		stdin   string
+		wantOut string
+		wantErr bool
+	}{
+		{name: "Inspect rsa", args: []string{"inspect", path("rsa.pem")}, wantOut: "Fingerprint: " + fingerprint},
+		{name: "Inspect rsa public", args: []string{"inspect", path("rsa.pub")}, wantOut: "Private: false"},
+		{name: "Inspect jwk", args: []string{"inspect", path("rsa.jwk")}, wantOut: "Fingerprint: " + fingerprint},
+		{name: "Inspect ecdsa", args: []string{"inspect", path("ec.pem")}, wantOut: "Size: 384 bits"},
+		{name: "Inspect ssh public", args: []string{"inspect", path("ed.pub")}, wantOut: "Type: Ed25519"},
+		{name: "Inspect stdin", args: []string{"inspect"}, stdin: string(b), wantOut: "Size: 2048 bits"},
+		{name: "Inspect encrypted", args: []string{"inspect", "-passphrase-file", path("passphrase"), path("rsa.enc")}, wantOut: "Fingerprint: " + fingerprint},
+		{name: "Inspect encrypted without passphrase", args: []string{"inspect", path("rsa.enc")}, wantErr: true},
+		{name: "Decrypt wrong passphrase", args: []string{"decrypt", "-passphrase-file", path("wrong"), path("rsa.enc")}, wantErr: true},
+		{name: "Decrypt", args: []string{"decrypt", "-passphrase-file", path("passphrase"), "-format", "pkcs1", path("rsa.enc")}, wantOut: string(b)},
+		{name: "Convert jwk to pkcs1", args: []string{"convert", "-format", "pkcs1", path("rsa.jwk")}, wantOut: string(b)},
+		{name: "Convert to pkcs8", args: []string{"convert", "-format", "pkcs8", path("rsa.pem")}, wantOut: "BEGIN PRIVATE KEY"},
+		{name: "Convert to ssh public", args: []string{"convert", "-format", "ssh", "-public", path("rsa.pem")}, wantOut: "ssh-rsa "},
+		{name: "Convert ssh private", args: []string{"convert", "-format", "pkcs8", path("ed.key")}, wantOut: "BEGIN PRIVATE KEY"},
+		{name: "Convert ecdsa to jwk", args: []string{"convert", "-format", "jwk", path("ec.pem")}, wantErr: true},
+		{name: "Convert without format", args: []string{"convert", path("rsa.pem")}, wantErr: true},
+		{name: "Decrypt rotated", args: []string{"decrypt", "-passphrase-file", path("new"), "-format", "pkcs1", path("rsa.rot")}, wantOut: string(b)},
+		{name: "Decrypt rotated old passphrase", args: []string{"decrypt", "-passphrase-file", path("passphrase"), path("rsa.rot")}, wantErr: true},
+		{name: "Inspect encrypted ssh", args: []string{"inspect", "-passphrase-file", path("passphrase"), path("ed.enc")}, wantOut: "Type: Ed25519"},
+		{name: "Inspect encrypted ssh without passphrase", args: []string{"inspect", path("ed.enc")}, wantErr: true},
+		{name: "Generate encrypted jwk", args: []string{"generate", "-format", "jwk", "-encrypt", "-passphrase-file", path("passphrase")}, wantErr: true},
+		{name: "Encrypt unsupported format", args: []string{"encrypt", "-format", "pkcs1", "-passphrase-file", path("passphrase"), path("rsa.pem")}, wantErr: true},
+		{name: "Encrypt without passphrase", args: []string{"encrypt", path("rsa.pem")}, wantErr: true},
+		{name: "Encrypt public key", args: []string{"encrypt", "-passphrase-file", path("passphrase"), path("rsa.pub")}, wantErr: true},
+		{name: "Generate small key", args: []string{"generate", "-bits", "1024"}, wantErr: true},
+		{name: "Generate unknown type", args: []string{"generate", "-type", "dsa"}, wantErr: true},
+		{name: "Unknown command", args: []string{"sign"}, wantErr: true},
+		{name: "No command", args: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := run(tt.args, strings.NewReader(tt.stdin), &out)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("run(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if !strings.Contains(out.String(), tt.wantOut) {
+				t.Errorf("run(%v) output = %q, want to contain %q", tt.args, out.String(), tt.wantOut)
+			}
+		})
+	}
+}
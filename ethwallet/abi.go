@@ -0,0 +1,148 @@
+package eth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	// ErrInvalidABI is returned when a contract ABI cannot be parsed.
+	ErrInvalidABI = errors.New("invalid abi")
+	// ErrUnknownMethod is returned when the ABI has no method with the name or selector.
+	ErrUnknownMethod = errors.New("unknown method")
+	// ErrUnknownEvent is returned when the ABI has no event matching a log.
+	ErrUnknownEvent = errors.New("unknown event")
+)
+
+// selectorSize is the size of the method selector that starts calldata
+const selectorSize = 4
+
+// Contract is a contract ABI, optionally bound to the address of a deployed contract.
+type Contract struct {
+	ABI     abi.ABI
+	Address common.Address
+}
+
+// NewContract parses the ABI JSON of a contract deployed at address. The
+// address may be empty when the contract is only used to encode and decode.
+func NewContract(abiJSON []byte, address string) (*Contract, error) {
+	parsed, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse abi %w: %w", err, ErrInvalidABI)
+	}
+
+	c := &Contract{ABI: parsed}
+	if address != "" {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("contract %q: %w", address, ErrInvalidAddress)
+		}
+		c.Address = common.HexToAddress(address)
+	}
+
+	return c, nil
+}
+
+// Pack encodes a call of the method with the arguments into calldata.
+func (c *Contract) Pack(method string, args ...any) ([]byte, error) {
+	if _, ok := c.ABI.Methods[method]; !ok {
+		return nil, fmt.Errorf("%q: %w", method, ErrUnknownMethod)
+	}
+
+	data, err := c.ABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack %s: %w", method, err)
+	}
+
+	return data, nil
+}
+
+// Unpack decodes the data returned by a call of the method.
+func (c *Contract) Unpack(method string, data []byte) ([]any, error) {
+	if _, ok := c.ABI.Methods[method]; !ok {
+		return nil, fmt.Errorf("%q: %w", method, ErrUnknownMethod)
+	}
+
+	values, err := c.ABI.Unpack(method, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s: %w", method, err)
+	}
+
+	return values, nil
+}
+
+// DecodeCall decodes calldata into the name of the method called and its arguments by name.
+func (c *Contract) DecodeCall(data []byte) (string, map[string]any, error) {
+	if len(data) < selectorSize {
+		return "", nil, fmt.Errorf("calldata of %d bytes: %w", len(data), ErrUnknownMethod)
+	}
+
+	method, err := c.ABI.MethodById(data[:selectorSize])
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", err, ErrUnknownMethod)
+	}
+
+	args := map[string]any{}
+	if err := method.Inputs.UnpackIntoMap(args, data[selectorSize:]); err != nil {
+		return "", nil, fmt.Errorf("failed to unpack %s: %w", method.Name, err)
+	}
+
+	return method.Name, args, nil
+}
+
+// DecodeLog decodes an event log into the event name and its indexed and
+// non-indexed fields by name.
+func (c *Contract) DecodeLog(log types.Log) (string, map[string]any, error) {
+	if len(log.Topics) == 0 {
+		return "", nil, fmt.Errorf("log without topics: %w", ErrUnknownEvent)
+	}
+
+	event, err := c.ABI.EventByID(log.Topics[0])
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %w", err, ErrUnknownEvent)
+	}
+
+	fields := map[string]any{}
+	if len(log.Data) > 0 {
+		if err := c.ABI.UnpackIntoMap(fields, event.Name, log.Data); err != nil {
+			return "", nil, fmt.Errorf("failed to unpack %s: %w", event.Name, err)
+		}
+	}
+
+	var indexed abi.Arguments
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+
+	if err := abi.ParseTopicsIntoMap(fields, indexed, log.Topics[1:]); err != nil {
+		return "", nil, fmt.Errorf("failed to parse %s topics: %w", event.Name, err)
+	}
+
+	return event.Name, fields, nil
+}
+
+// NewTransaction builds an unsigned dynamic-fee transaction calling the method
+// of the bound contract. To and Data of the parameters are set from the
+// contract address and the packed call, the rest is used as is.
+func (c *Contract) NewTransaction(chainID *big.Int, p TxParams, method string, args ...any) (*types.Transaction, error) {
+	if c.Address == (common.Address{}) {
+		return nil, fmt.Errorf("contract is not bound to an address: %w", ErrInvalidAddress)
+	}
+
+	data, err := c.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	p.To = c.Address.Hex()
+	p.Data = data
+
+	return NewDynamicFeeTx(chainID, p)
+}
@@ -0,0 +1,202 @@
+package eth
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// erc20ABI is the part of the ERC-20 ABI used by the tests
+const erc20ABI = `[
+	{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+	{"type":"event","name":"Transfer","anonymous":false,"inputs":[{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},{"name":"value","type":"uint256","indexed":false}]}
+]`
+
+const (
+	testToken     = "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"
+	testRecipient = "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"
+)
+
+func TestContract_Pack(t *testing.T) {
+	c, err := NewContract([]byte(erc20ABI), testToken)
+	if err != nil {
+		t.Fatalf("NewContract() error = %v", err)
+	}
+
+	data, err := c.Pack("transfer", common.HexToAddress(testRecipient), big.NewInt(1000))
+	if err != nil {
+		t.Fatalf("Pack() error = %v", err)
+	}
+
+	want := "0xa9059cbb" +
+		"000000000000000000000000bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb" +
+		"00000000000000000000000000000000000000000000000000000000000003e8"
+	if got := hexutil.Encode(data); got != want {
+		t.Errorf("Pack() = %s, want %s", got, want)
+	}
+
+	method, args, err := c.DecodeCall(data)
+	if err != nil {
+		t.Fatalf("DecodeCall() error = %v", err)
+	}
+	if method != "transfer" || args["to"] != common.HexToAddress(testRecipient) || args["value"].(*big.Int).Int64() != 1000 {
+		t.Errorf("DecodeCall() = %s, %v", method, args)
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		args    []any
+		wantErr error
+	}{
+		{name: "Unknown method", method: "approve", args: []any{common.HexToAddress(testRecipient), big.NewInt(1)}, wantErr: ErrUnknownMethod},
+		{name: "Missing argument", method: "transfer", args: []any{common.HexToAddress(testRecipient)}},
+		{name: "Wrong argument type", method: "transfer", args: []any{testRecipient, big.NewInt(1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Pack(tt.method, tt.args...)
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("Pack() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, _, err := c.DecodeCall([]byte{0x09, 0x5e, 0xa7, 0xb3}); !errors.Is(err, ErrUnknownMethod) {
+		t.Errorf("DecodeCall() error = %v, want %v", err, ErrUnknownMethod)
+	}
+
+	if _, err := NewContract([]byte(`{"type":`), ""); !errors.Is(err, ErrInvalidABI) {
+		t.Errorf("NewContract() error = %v, want %v", err, ErrInvalidABI)
+	}
+}
+
+func TestContract_Unpack(t *testing.T) {
+	c, err := NewContract([]byte(erc20ABI), "")
+	if err != nil {
+		t.Fatalf("NewContract() error = %v", err)
+	}
+
+	values, err := c.Unpack("balanceOf", common.LeftPadBytes(big.NewInt(42e6).Bytes(), 32))
+	if err != nil {
+		t.Fatalf("Unpack() error = %v", err)
+	}
+	if len(values) != 1 || values[0].(*big.Int).Int64() != 42e6 {
+		t.Errorf("Unpack() = %v, want [42000000]", values)
+	}
+
+	if _, err := c.Unpack("balanceOf", []byte{1}); err == nil {
+		t.Error("Unpack(short data) error = nil, want error")
+	}
+
+	if _, err := c.Unpack("totalSupply", nil); !errors.Is(err, ErrUnknownMethod) {
+		t.Errorf("Unpack() error = %v, want %v", err, ErrUnknownMethod)
+	}
+}
+
+func TestContract_DecodeLog(t *testing.T) {
+	c, err := NewContract([]byte(erc20ABI), testToken)
+	if err != nil {
+		t.Fatalf("NewContract() error = %v", err)
+	}
+
+	from := common.HexToAddress("0x9858EfFD232B4033E47d90003D41EC34EcaEda94")
+	to := common.HexToAddress(testRecipient)
+	transfer := types.Log{
+		Address: c.Address,
+		Topics: []common.Hash{
+			common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"),
+			common.BytesToHash(from.Bytes()),
+			common.BytesToHash(to.Bytes()),
+		},
+		Data: common.LeftPadBytes(big.NewInt(1000).Bytes(), 32),
+	}
+
+	name, fields, err := c.DecodeLog(transfer)
+	if err != nil {
+		t.Fatalf("DecodeLog() error = %v", err)
+	}
+	if name != "Transfer" || fields["from"] != from || fields["to"] != to || fields["value"].(*big.Int).Int64() != 1000 {
+		t.Errorf("DecodeLog() = %s, %v", name, fields)
+	}
+
+	tests := []struct {
+		name string
+		log  types.Log
+	}{
+		{name: "No topics", log: types.Log{Data: transfer.Data}},
+		{name: "Unknown event", log: types.Log{Topics: []common.Hash{common.HexToHash("0x8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925")}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := c.DecodeLog(tt.log); !errors.Is(err, ErrUnknownEvent) {
+				t.Errorf("DecodeLog() error = %v, want %v", err, ErrUnknownEvent)
+			}
+		})
+	}
+
+	if _, _, err := c.DecodeLog(types.Log{Topics: transfer.Topics[:2], Data: transfer.Data}); err == nil {
+		t.Error("DecodeLog(missing topic) error = nil, want error")
+	}
+}
+
+func TestContract_NewTransaction(t *testing.T) {
+	w, err := New(WithMnemonic(testMnemonic))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	c, err := NewContract([]byte(erc20ABI), testToken)
+	if err != nil {
+		t.Fatalf("NewContract() error = %v", err)
+	}
+
+	chainID := big.NewInt(1)
+	params := TxParams{
+		Nonce:     7,
+		Gas:       60000,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(30e9),
+	}
+
+	tx, err := c.NewTransaction(chainID, params, "transfer", common.HexToAddress(testRecipient), big.NewInt(1000))
+	if err != nil {
+		t.Fatalf("NewTransaction() error = %v", err)
+	}
+	if tx.To() == nil || *tx.To() != c.Address || tx.Nonce() != 7 || tx.Value().Sign() != 0 {
+		t.Errorf("NewTransaction() = to %v, nonce %d, value %s", tx.To(), tx.Nonce(), tx.Value())
+	}
+
+	method, _, err := c.DecodeCall(tx.Data())
+	if err != nil || method != "transfer" {
+		t.Errorf("DecodeCall(tx.Data()) = %s, %v", method, err)
+	}
+
+	signed, err := w.SignTransaction(chainID, tx)
+	if err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+
+	sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+	if err != nil || sender.Hex() != w.Address() {
+		t.Errorf("Sender() = %s, %v, want %s", sender.Hex(), err, w.Address())
+	}
+
+	unbound, err := NewContract([]byte(erc20ABI), "")
+	if err != nil {
+		t.Fatalf("NewContract() error = %v", err)
+	}
+	if _, err := unbound.NewTransaction(chainID, params, "transfer", common.HexToAddress(testRecipient), big.NewInt(1)); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("NewTransaction(unbound) error = %v, want %v", err, ErrInvalidAddress)
+	}
+	if _, err := NewContract([]byte(erc20ABI), "0x1234"); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("NewContract() error = %v, want %v", err, ErrInvalidAddress)
+	}
+}
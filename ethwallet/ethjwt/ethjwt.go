@@ -0,0 +1,251 @@
+// Package ethjwt issues and verifies ES256K JSON Web Tokens signed by an
+// eth.Wallet. The iss claim holds the did:pkh identifier of the signer and
+// tokens are only accepted from addresses the verifier expects.
+package ethjwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	eth "github.com/euforic/pkg-go/ethwallet"
+	"github.com/golang-jwt/jwt"
+)
+
+var (
+	// ErrInvalidKey is returned when the signing or verification key has an unsupported type.
+	ErrInvalidKey = errors.New("invalid key for ES256K")
+	// ErrInvalidIssuer is returned when the iss claim is not a did:pkh identifier or an address.
+	ErrInvalidIssuer = errors.New("invalid issuer")
+	// ErrUnknownIssuer is returned when the iss claim names an address that is not accepted.
+	ErrUnknownIssuer = errors.New("unknown issuer")
+	// ErrMissingExpiration is returned when a token has no exp claim.
+	ErrMissingExpiration = errors.New("token has no expiration")
+)
+
+const (
+	// signatureSize is the size of an ES256K signature, R and S without the recovery id
+	signatureSize = 64
+	// didPKHPrefix is the prefix of did:pkh identifiers of EVM accounts
+	didPKHPrefix = "did:pkh:eip155:"
+)
+
+// SigningMethodES256K is ECDSA on secp256k1 with SHA-256 (RFC 8812).
+var SigningMethodES256K = &SigningMethodSecp256k1{} //nolint:gochecknoglobals
+
+func init() { //nolint:gochecknoinits
+	jwt.RegisterSigningMethod(SigningMethodES256K.Alg(), func() jwt.SigningMethod {
+		return SigningMethodES256K
+	})
+}
+
+// Signer signs ES256K signing strings, as eth.Wallet does, without exposing its key.
+type Signer interface {
+	SignES256K(signingString string) ([]byte, error)
+}
+
+// SigningMethodSecp256k1 implements the ES256K signing method. Tokens are
+// signed with a Signer and verified with an *ecdsa.PublicKey or, by
+// recovering the signer, with a common.Address or hex address string.
+type SigningMethodSecp256k1 struct{}
+
+// Alg returns the ES256K algorithm name.
+func (m *SigningMethodSecp256k1) Alg() string {
+	return "ES256K"
+}
+
+// Sign signs the signing string with a Signer and returns the encoded R || S signature.
+func (m *SigningMethodSecp256k1) Sign(signingString string, key interface{}) (string, error) {
+	signer, ok := key.(Signer)
+	if !ok {
+		return "", fmt.Errorf("%T: %w", key, ErrInvalidKey)
+	}
+
+	signature, err := signer.SignES256K(signingString)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	if len(signature) != signatureSize {
+		return "", fmt.Errorf("signature length %d: %w", len(signature), ErrInvalidKey)
+	}
+
+	return jwt.EncodeSegment(signature), nil
+}
+
+// Verify checks the encoded signature of the signing string against a public
+// key or address. As RFC 8812 does not require low S values, high S
+// signatures are accepted.
+func (m *SigningMethodSecp256k1) Verify(signingString, signature string, key interface{}) error {
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return fmt.Errorf("failed to decode signature: %w", err)
+	}
+
+	if len(sig) != signatureSize {
+		return jwt.ErrSignatureInvalid
+	}
+
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if !crypto.ValidateSignatureValues(0, r, s, false) {
+		return jwt.ErrSignatureInvalid
+	}
+
+	// go-ethereum only verifies and recovers low S signatures, (R, N - S)
+	// is the equivalent signature of the same key
+	if n := crypto.S256().Params().N; s.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+		s.Sub(n, s).FillBytes(sig[32:])
+	}
+
+	hash := sha256.Sum256([]byte(signingString))
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if !crypto.VerifySignature(crypto.FromECDSAPub(k), hash[:], sig) {
+			return jwt.ErrSignatureInvalid
+		}
+
+		return nil
+	case common.Address:
+		return verifyAddress(hash[:], sig, k)
+	case string:
+		if !common.IsHexAddress(k) {
+			return fmt.Errorf("%q: %w", k, ErrInvalidKey)
+		}
+
+		return verifyAddress(hash[:], sig, common.HexToAddress(k))
+	default:
+		return fmt.Errorf("%T: %w", key, ErrInvalidKey)
+	}
+}
+
+// Issuer returns the did:pkh identifier of the address on the chain, used as the iss claim.
+func Issuer(chainID int64, address string) string {
+	return didPKHPrefix + strconv.FormatInt(chainID, 10) + ":" + common.HexToAddress(address).Hex()
+}
+
+// IssuerAddress returns the checksummed address named by an iss claim holding
+// a did:pkh identifier or a hex address.
+func IssuerAddress(iss string) (string, error) {
+	_, address, err := parseIssuer(iss)
+
+	return address, err
+}
+
+// parseIssuer returns the chain id and checksummed address of an iss claim.
+// The chain id is zero for a hex address.
+func parseIssuer(iss string) (int64, string, error) {
+	var chainID int64
+	address := iss
+	if rest, ok := strings.CutPrefix(iss, didPKHPrefix); ok {
+		id, addr, found := strings.Cut(rest, ":")
+		parsed, err := strconv.ParseInt(id, 10, 64)
+		if !found || err != nil || parsed <= 0 {
+			return 0, "", fmt.Errorf("%q: %w", iss, ErrInvalidIssuer)
+		}
+		chainID, address = parsed, addr
+	}
+
+	if !common.IsHexAddress(address) {
+		return 0, "", fmt.Errorf("%q: %w", iss, ErrInvalidIssuer)
+	}
+
+	return chainID, common.HexToAddress(address).Hex(), nil
+}
+
+// NewToken signs a token with the wallet. The iss claim is set to the
+// did:pkh identifier of the wallet, iat to now and exp to now plus ttl.
+func NewToken(w *eth.Wallet, chainID int64, ttl time.Duration, claims jwt.MapClaims) (string, error) {
+	now := time.Now()
+
+	c := jwt.MapClaims{}
+	for k, v := range claims {
+		c[k] = v
+	}
+	c["iss"] = Issuer(chainID, w.Address())
+	c["iat"] = now.Unix()
+	c["exp"] = now.Add(ttl).Unix()
+
+	token, err := jwt.NewWithClaims(SigningMethodES256K, c).SignedString(w)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return token, nil
+}
+
+// Verify parses an ES256K token and checks that its iss claim is the did:pkh
+// identifier of one of the addresses on the chain, that it was signed by that
+// address and that it has not expired. A token without exp is rejected. It
+// returns the claims and the checksummed address of the signer.
+func Verify(tokenString string, chainID int64, addresses ...string) (jwt.MapClaims, string, error) {
+	var address string
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != SigningMethodES256K {
+			return nil, fmt.Errorf("unexpected signing method %v: %w", token.Header["alg"], ErrInvalidKey)
+		}
+
+		claims, _ := token.Claims.(jwt.MapClaims)
+		iss, _ := claims["iss"].(string)
+
+		issChainID, issAddress, err := parseIssuer(iss)
+		if err != nil {
+			return nil, err
+		}
+
+		if issChainID != chainID {
+			return nil, fmt.Errorf("%q is not on chain %d: %w", iss, chainID, ErrInvalidIssuer)
+		}
+
+		if !slices.ContainsFunc(addresses, func(a string) bool {
+			return common.IsHexAddress(a) && common.HexToAddress(a).Hex() == issAddress
+		}) {
+			return nil, fmt.Errorf("%s: %w", issAddress, ErrUnknownIssuer)
+		}
+
+		switch claims["exp"].(type) {
+		case float64, json.Number:
+		default:
+			return nil, ErrMissingExpiration
+		}
+		address = issAddress
+
+		// The signature is checked against the expected address, not a key taken from the token
+		return common.HexToAddress(address), nil
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to verify token: %w", err)
+	}
+
+	claims, _ := token.Claims.(jwt.MapClaims)
+
+	return claims, address, nil
+}
+
+// verifyAddress recovers the signer of the hash with each recovery id and compares it with the address.
+func verifyAddress(hash, sig []byte, address common.Address) error {
+	withV := make([]byte, crypto.SignatureLength)
+	copy(withV, sig)
+
+	for _, v := range []byte{0, 1} {
+		withV[crypto.RecoveryIDOffset] = v
+
+		pub, err := crypto.SigToPub(hash, withV)
+		if err == nil && crypto.PubkeyToAddress(*pub) == address {
+			return nil
+		}
+	}
+
+	return jwt.ErrSignatureInvalid
+}
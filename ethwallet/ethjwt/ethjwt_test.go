@@ -0,0 +1,163 @@
+package ethjwt
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	eth "github.com/euforic/pkg-go/ethwallet"
+	"github.com/golang-jwt/jwt"
+)
+
+func TestVerify(t *testing.T) {
+	key, err := eth.NewHDKey("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Fatalf("NewHDKey() error = %v", err)
+	}
+	wallets, err := key.Accounts(0, 2)
+	if err != nil {
+		t.Fatalf("Accounts() error = %v", err)
+	}
+	w, other := wallets[0], wallets[1]
+
+	token, err := NewToken(w, 1, time.Hour, jwt.MapClaims{"sub": "user-1"})
+	if err != nil {
+		t.Fatalf("NewToken() error = %v", err)
+	}
+
+	claims, address, err := Verify(token, 1, w.Address())
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if address != w.Address() || claims["iss"] != "did:pkh:eip155:1:"+w.Address() || claims["sub"] != "user-1" {
+		t.Errorf("Verify() = %v, %s, want iss of %s", claims, address, w.Address())
+	}
+
+	pub, err := crypto.UnmarshalPubkey(hexutil.MustDecode(w.PublicKeyStr()))
+	if err != nil {
+		t.Fatalf("UnmarshalPubkey() error = %v", err)
+	}
+	if _, err := jwt.Parse(token, func(*jwt.Token) (interface{}, error) { return pub, nil }); err != nil {
+		t.Errorf("jwt.Parse(public key) error = %v", err)
+	}
+
+	// A token of another key naming itself as issuer is only accepted when its address is
+	foreign, err := NewToken(other, 1, time.Hour, nil)
+	if err != nil {
+		t.Fatalf("NewToken() error = %v", err)
+	}
+	if _, address, err := Verify(foreign, 1, w.Address(), strings.ToLower(other.Address())); err != nil || address != other.Address() {
+		t.Errorf("Verify() = %s, %v, want %s", address, err, other.Address())
+	}
+
+	// Signers are not required to use low S values, the (R, N - S) twin of a signature is valid
+	parts := strings.Split(token, ".")
+	sig, err := jwt.DecodeSegment(parts[2])
+	if err != nil {
+		t.Fatalf("DecodeSegment() error = %v", err)
+	}
+	new(big.Int).Sub(crypto.S256().Params().N, new(big.Int).SetBytes(sig[32:])).FillBytes(sig[32:])
+	highS := parts[0] + "." + parts[1] + "." + jwt.EncodeSegment(sig)
+	if _, _, err := Verify(highS, 1, w.Address()); err != nil {
+		t.Errorf("Verify(high S) error = %v", err)
+	}
+	if _, err := jwt.Parse(highS, func(*jwt.Token) (interface{}, error) { return pub, nil }); err != nil {
+		t.Errorf("jwt.Parse(high S, public key) error = %v", err)
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+	sign := func(w *eth.Wallet, claims jwt.MapClaims) string {
+		t.Helper()
+
+		token, err := jwt.NewWithClaims(SigningMethodES256K, claims).SignedString(w)
+		if err != nil {
+			t.Fatalf("SignedString() error = %v", err)
+		}
+
+		return token
+	}
+	otherChain, err := NewToken(w, 5, time.Hour, nil)
+	if err != nil {
+		t.Fatalf("NewToken() error = %v", err)
+	}
+	expired, err := NewToken(w, 1, -time.Minute, nil)
+	if err != nil {
+		t.Fatalf("NewToken() error = %v", err)
+	}
+	hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"iss": Issuer(1, w.Address()), "exp": exp}).SignedString([]byte(w.Address()))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+	tampered := parts[0] + "." + strings.TrimRight(jwt.EncodeSegment([]byte(`{"iss":"`+Issuer(1, w.Address())+`","sub":"admin","exp":`+strconv.FormatInt(exp, 10)+`}`)), "=") + "." + parts[2]
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "Foreign key", token: foreign, wantErr: ErrUnknownIssuer},
+		{name: "Foreign key naming the address", token: sign(other, jwt.MapClaims{"iss": Issuer(1, w.Address()), "exp": exp}), wantErr: jwt.ErrSignatureInvalid},
+		{name: "Tampered claims", token: tampered, wantErr: jwt.ErrSignatureInvalid},
+		{name: "Other chain", token: otherChain, wantErr: ErrInvalidIssuer},
+		{name: "Address issuer", token: sign(w, jwt.MapClaims{"iss": w.Address(), "exp": exp}), wantErr: ErrInvalidIssuer},
+		{name: "Missing expiration", token: sign(w, jwt.MapClaims{"iss": Issuer(1, w.Address())}), wantErr: ErrMissingExpiration},
+		{name: "Invalid expiration", token: sign(w, jwt.MapClaims{"iss": Issuer(1, w.Address()), "exp": "never"}), wantErr: ErrMissingExpiration},
+		{name: "Expired", token: expired},
+		{name: "HMAC token", token: hmac, wantErr: ErrInvalidKey},
+		{name: "Missing issuer", token: sign(w, jwt.MapClaims{"sub": "user-1", "exp": exp}), wantErr: ErrInvalidIssuer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Verify(tt.token, 1, w.Address())
+			if err == nil {
+				t.Fatalf("Verify() error = nil, want error")
+			}
+
+			var ve *jwt.ValidationError
+			if tt.wantErr != nil && (!errors.As(err, &ve) || !errors.Is(ve.Inner, tt.wantErr)) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	var ve *jwt.ValidationError
+	if _, _, err := Verify(token, 1); !errors.As(err, &ve) || !errors.Is(ve.Inner, ErrUnknownIssuer) {
+		t.Errorf("Verify() without addresses error = %v, want %v", err, ErrUnknownIssuer)
+	}
+}
+
+func TestIssuerAddress(t *testing.T) {
+	const address = "0x9858EfFD232B4033E47d90003D41EC34EcaEda94"
+
+	tests := []struct {
+		iss     string
+		wantErr bool
+	}{
+		{iss: "did:pkh:eip155:1:" + address},
+		{iss: "did:pkh:eip155:137:0x9858effd232b4033e47d90003d41ec34ecaeda94"},
+		{iss: address},
+		{iss: "did:pkh:eip155:one:" + address, wantErr: true},
+		{iss: "did:pkh:eip155:1", wantErr: true},
+		{iss: "did:pkh:eip155:0:" + address, wantErr: true},
+		{iss: "did:web:example.com", wantErr: true},
+		{iss: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.iss, func(t *testing.T) {
+			got, err := IssuerAddress(tt.iss)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("IssuerAddress(%q) error = %v, wantErr %v", tt.iss, err, tt.wantErr)
+			}
+			if err == nil && got != address {
+				t.Errorf("IssuerAddress(%q) = %s, want %s", tt.iss, got, address)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package eth
 
 import (
 	"crypto/ecdsa"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"math/big"
@@ -11,7 +12,6 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/tyler-smith/go-bip32"
 	"github.com/tyler-smith/go-bip39"
 )
 
@@ -29,6 +29,7 @@ var (
 // Wallet represents a cryptocurrency wallet
 type Wallet struct {
 	privateKey *ecdsa.PrivateKey
+	path       accounts.DerivationPath
 }
 
 // WalletOpt is a functional option for creating a wallet.
@@ -48,59 +49,21 @@ func WithPrivateKey(privateKey string) WalletOpt {
 	}
 }
 
-// WithMnemonic creates a wallet from a mnemonic.
-func WithMnemonic(mnemonic string) WalletOpt {
+// WithMnemonic creates a wallet from a mnemonic, deriving the key at
+// DefaultDerivationPath unless WithDerivationPath is set.
+func WithMnemonic(mnemonic string, opts ...MnemonicOpt) WalletOpt {
 	return func(w *Wallet) error {
-		if mnemonic == "" {
-			return fmt.Errorf("mnemonic is required: %w", ErrInvalidMnemonic)
-		}
-
-		// Use the provided mnemonic
-		if !bip39.IsMnemonicValid(mnemonic) {
-			return fmt.Errorf("invalid mnemonic: %w", ErrInvalidMnemonic)
-		}
-
-		seed := bip39.NewSeed(mnemonic, "")
-
-		// Generate a master key from the seed
-		masterKey, err := bip32.NewMasterKey(seed)
-		if err != nil {
-			return fmt.Errorf("failed to generate master key %w: %w", err, ErrInvalidMnemonic)
-		}
-
-		// Derive the Ethereum key (BIP-44: m/44'/60'/0'/0/0)
-		purpose, err := masterKey.NewChildKey(bip32.FirstHardenedChild + 44) //nolint:mnd
-		if err != nil {
-			return fmt.Errorf("failed to derive purpose key %w: %w", err, ErrInvalidMnemonic)
-		}
-
-		// Ethereum uses coin type 60
-		coinType, err := purpose.NewChildKey(bip32.FirstHardenedChild + 60) //nolint:mnd
+		key, err := NewHDKey(mnemonic, opts...)
 		if err != nil {
-			return fmt.Errorf("failed to derive coin type key %w: %w", err, ErrInvalidMnemonic)
+			return err
 		}
 
-		account, err := coinType.NewChildKey(bip32.FirstHardenedChild)
+		derived, err := key.Wallet()
 		if err != nil {
-			return fmt.Errorf("failed to derive account key %w: %w", err, ErrInvalidMnemonic)
+			return err
 		}
 
-		change, err := account.NewChildKey(0)
-		if err != nil {
-			return fmt.Errorf("failed to derive change key %w: %w", err, ErrInvalidMnemonic)
-		}
-
-		addressIndex, err := change.NewChildKey(0)
-		if err != nil {
-			return fmt.Errorf("failed to derive address index key %w: %w", err, ErrInvalidMnemonic)
-		}
-
-		privateKey, err := crypto.ToECDSA(addressIndex.Key)
-		if err != nil {
-			return fmt.Errorf("failed to convert to ECDSA %w: %w", err, ErrInvalidMnemonic)
-		}
-
-		w.privateKey = privateKey
+		*w = *derived
 
 		return nil
 	}
@@ -146,9 +109,25 @@ func (w Wallet) SignMessage(message string) (string, error) {
 	return hexutil.Encode(signature), nil
 }
 
+// SignES256K signs a JWS signing string with ES256K (RFC 8812), returning the
+// 64 byte [R || S] signature of its SHA-256 hash. The hash is computed here so
+// the wallet never signs an arbitrary digest, such as a transaction hash.
+func (w Wallet) SignES256K(signingString string) ([]byte, error) {
+	hash := sha256.Sum256([]byte(signingString))
+
+	signature, err := crypto.Sign(hash[:], w.privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign ES256K: %w", err)
+	}
+
+	return signature[:crypto.RecoveryIDOffset], nil
+}
+
 // SignTransaction signs a transaction with the provided private key and chain ID.
+// The signer is chosen from the transaction type, so legacy (EIP-155), access
+// list (EIP-2930), dynamic-fee (EIP-1559) and blob (EIP-4844) transactions can be signed.
 func (w Wallet) SignTransaction(chainID *big.Int, tx *types.Transaction) (*types.Transaction, error) {
-	signer := types.NewEIP155Signer(chainID)
+	signer := signerFor(chainID, tx)
 	signedTx, err := types.SignTx(tx, signer, w.privateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign transaction: %w", err)
@@ -157,26 +136,25 @@ func (w Wallet) SignTransaction(chainID *big.Int, tx *types.Transaction) (*types
 	return signedTx, nil
 }
 
-// VerifySignature verifies the signature of a message.
+// VerifySignature verifies that the wallet signed the message with SignMessage.
 func (w Wallet) VerifySignature(signatureHex, message string) error {
-	signature, err := hexutil.Decode(signatureHex)
-	if err != nil {
-		return fmt.Errorf("failed to decode signature: %w", err)
-	}
-
-	signature[crypto.RecoveryIDOffset] -= 27 // Transform yellow paper V from 27/28 to 0/1
+	return VerifyAddressSignature(w.Address(), signatureHex, message)
+}
 
-	messageHash := accounts.TextHash([]byte(message))
+// VerifyAddressSignature verifies that the address signed the message with
+// EIP-191 personal_sign. V may be encoded as 0/1 or 27/28.
+func VerifyAddressSignature(address, signatureHex, message string) error {
+	if !common.IsHexAddress(address) {
+		return fmt.Errorf("%q: %w", address, ErrInvalidAddress)
+	}
 
-	pubKey, err := crypto.SigToPub(messageHash, signature)
+	signer, err := recoverAddress(accounts.TextHash([]byte(message)), signatureHex)
 	if err != nil {
-		return fmt.Errorf("failed to recover public key: %w", err)
+		return err
 	}
 
-	fromAddress := crypto.PubkeyToAddress(*pubKey).Hex()
-
-	if common.HexToAddress(fromAddress) != crypto.PubkeyToAddress(*pubKey) {
-		return ErrInvalidSignature
+	if signer != common.HexToAddress(address) {
+		return fmt.Errorf("signed by %s: %w", signer.Hex(), ErrInvalidSignature)
 	}
 
 	return nil
@@ -202,19 +180,50 @@ func NewMnemonic() (string, error) {
 
 // SignatureAddress returns the address of the signer of a message.
 func SignatureAddress(signatureHex, message string) (string, error) {
-	signature, err := hexutil.Decode(signatureHex)
+	address, err := recoverAddress(accounts.TextHash([]byte(message)), signatureHex)
 	if err != nil {
-		return "", fmt.Errorf("failed to decode signature: %w", err)
+		return "", err
 	}
 
-	signature[crypto.RecoveryIDOffset] -= 27 // Transform yellow paper V from 27/28 to 0/1
+	return address.Hex(), nil
+}
 
-	messageHash := accounts.TextHash([]byte(message))
+// recoverAddress recovers the address that signed the hash. The signature
+// must be 65 bytes with V as 0/1 or 27/28 and a low S value (EIP-2).
+func recoverAddress(hash []byte, signatureHex string) (common.Address, error) {
+	decoded, err := hexutil.Decode(signatureHex)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to decode signature %w: %w", err, ErrInvalidSignature)
+	}
+
+	if len(decoded) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("signature length %d: %w", len(decoded), ErrInvalidSignature)
+	}
+
+	// Work on a copy so V can be normalised without touching the decoded signature
+	signature := make([]byte, crypto.SignatureLength)
+	copy(signature, decoded)
+
+	v := signature[crypto.RecoveryIDOffset]
+	switch v {
+	case 0, 1:
+	case 27, 28: //nolint:mnd
+		v -= 27 // Transform yellow paper V from 27/28 to 0/1
+	default:
+		return common.Address{}, fmt.Errorf("signature V %d: %w", v, ErrInvalidSignature)
+	}
+	signature[crypto.RecoveryIDOffset] = v
+
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:64])
+	if !crypto.ValidateSignatureValues(v, r, s, true) {
+		return common.Address{}, fmt.Errorf("signature R or S out of range or S not low: %w", ErrInvalidSignature)
+	}
 
-	pubKey, err := crypto.SigToPub(messageHash, signature)
+	pubKey, err := crypto.SigToPub(hash, signature)
 	if err != nil {
-		return "", fmt.Errorf("failed to recover public key: %w", err)
+		return common.Address{}, fmt.Errorf("failed to recover public key %w: %w", err, ErrInvalidSignature)
 	}
 
-	return crypto.PubkeyToAddress(*pubKey).Hex(), nil
+	return crypto.PubkeyToAddress(*pubKey), nil
 }
@@ -0,0 +1,134 @@
+package eth
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestWallet_VerifySignature(t *testing.T) {
+	key, err := NewHDKey(testMnemonic)
+	if err != nil {
+		t.Fatalf("NewHDKey() error = %v", err)
+	}
+	wallets, err := key.Accounts(0, 2)
+	if err != nil {
+		t.Fatalf("Accounts() error = %v", err)
+	}
+	w, other := wallets[0], wallets[1]
+
+	signature, err := w.SignMessage("hello")
+	if err != nil {
+		t.Fatalf("SignMessage() error = %v", err)
+	}
+	otherSignature, err := other.SignMessage("hello")
+	if err != nil {
+		t.Fatalf("SignMessage() error = %v", err)
+	}
+
+	sig := hexutil.MustDecode(signature)
+
+	zeroV := append([]byte{}, sig...)
+	zeroV[crypto.RecoveryIDOffset] -= 27
+
+	badV := append([]byte{}, sig...)
+	badV[crypto.RecoveryIDOffset] = 29
+
+	// The malleable twin of a signature has S' = N - S and the other recovery id
+	highS := append([]byte{}, sig...)
+	s := new(big.Int).Sub(crypto.S256().Params().N, new(big.Int).SetBytes(sig[32:64]))
+	s.FillBytes(highS[32:64])
+	highS[crypto.RecoveryIDOffset] ^= 1
+
+	tests := []struct {
+		name      string
+		signature string
+		message   string
+		wantErr   bool
+	}{
+		{name: "Own signature", signature: signature, message: "hello"},
+		{name: "V as 0/1", signature: hexutil.Encode(zeroV), message: "hello"},
+		{name: "Other signer", signature: otherSignature, message: "hello", wantErr: true},
+		{name: "Other message", signature: signature, message: "hello!", wantErr: true},
+		{name: "Invalid V", signature: hexutil.Encode(badV), message: "hello", wantErr: true},
+		{name: "High S", signature: hexutil.Encode(highS), message: "hello", wantErr: true},
+		{name: "Short signature", signature: "0x1b", message: "hello", wantErr: true},
+		{name: "Empty signature", signature: "0x", message: "hello", wantErr: true},
+		{name: "Not hex", signature: "signature", message: "hello", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := w.VerifySignature(tt.signature, tt.message)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifySignature() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("VerifySignature() error = %v, want %v", err, ErrInvalidSignature)
+			}
+		})
+	}
+
+	// The high S twin is a valid ECDSA signature of the signer, only the low S rule rejects it
+	if !ecdsa.Verify(&w.privateKey.PublicKey, accounts.TextHash([]byte("hello")), new(big.Int).SetBytes(highS[:32]), s) {
+		t.Fatalf("ecdsa.Verify() = false, want the high S signature to verify")
+	}
+
+	for name, sig := range map[string][]byte{"High S": highS, "Invalid V": badV} {
+		if _, err := SignatureAddress(hexutil.Encode(sig), "hello"); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("SignatureAddress(%s) error = %v, want %v", name, err, ErrInvalidSignature)
+		}
+		if err := VerifyAddressSignature(w.Address(), hexutil.Encode(sig), "hello"); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("VerifyAddressSignature(%s) error = %v, want %v", name, err, ErrInvalidSignature)
+		}
+	}
+
+	if err := VerifyAddressSignature(other.Address(), otherSignature, "hello"); err != nil {
+		t.Errorf("VerifyAddressSignature() error = %v", err)
+	}
+	if err := VerifyAddressSignature("0x1234", otherSignature, "hello"); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("VerifyAddressSignature() error = %v, want %v", err, ErrInvalidAddress)
+	}
+
+	address, err := SignatureAddress(hexutil.Encode(zeroV), "hello")
+	if err != nil || address != w.Address() {
+		t.Errorf("SignatureAddress() = %s, %v, want %s", address, err, w.Address())
+	}
+}
+
+func TestWallet_SignES256K(t *testing.T) {
+	key, err := NewHDKey(testMnemonic)
+	if err != nil {
+		t.Fatalf("NewHDKey() error = %v", err)
+	}
+	w, err := key.Wallet()
+	if err != nil {
+		t.Fatalf("Wallet() error = %v", err)
+	}
+
+	signingString := "eyJhbGciOiJFUzI1NksifQ.eyJzdWIiOiJ3YWxsZXQifQ"
+
+	signature, err := w.SignES256K(signingString)
+	if err != nil {
+		t.Fatalf("SignES256K() error = %v", err)
+	}
+	if len(signature) != 64 {
+		t.Fatalf("SignES256K() length = %d, want 64", len(signature))
+	}
+
+	pub := crypto.FromECDSAPub(&w.privateKey.PublicKey)
+	hash := sha256.Sum256([]byte(signingString))
+	if !crypto.VerifySignature(pub, hash[:], signature) {
+		t.Errorf("VerifySignature() = false, want the signature of the SHA-256 hash")
+	}
+	// The signing string itself is hashed, it is never signed as a digest
+	if crypto.VerifySignature(pub, crypto.Keccak256([]byte(signingString)), signature) {
+		t.Errorf("VerifySignature() = true for the Keccak-256 hash, want false")
+	}
+}
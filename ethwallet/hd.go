@@ -0,0 +1,186 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip32"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// ErrInvalidDerivationPath is returned when the derivation path is invalid.
+var ErrInvalidDerivationPath = errors.New("invalid derivation path")
+
+// DefaultDerivationPath is the BIP-44 path of the first Ethereum account.
+const DefaultDerivationPath = "m/44'/60'/0'/0/0"
+
+// mnemonicOptions holds the settings used to derive a wallet from a mnemonic.
+type mnemonicOptions struct {
+	path       string
+	passphrase string
+}
+
+// MnemonicOpt is a functional option for WithMnemonic and NewHDKey.
+type MnemonicOpt func(*mnemonicOptions)
+
+// WithDerivationPath sets the BIP-32 derivation path, DefaultDerivationPath by default.
+// Relative paths such as "0/5" are appended to m/44'/60'/0'/0.
+func WithDerivationPath(path string) MnemonicOpt {
+	return func(o *mnemonicOptions) {
+		o.path = path
+	}
+}
+
+// WithPassphrase sets the BIP-39 passphrase, sometimes called the 25th word.
+func WithPassphrase(passphrase string) MnemonicOpt {
+	return func(o *mnemonicOptions) {
+		o.passphrase = passphrase
+	}
+}
+
+// HDKey is a BIP-32 master key from which many wallets can be derived
+// without running the BIP-39 seed derivation again.
+type HDKey struct {
+	master *bip32.Key
+	path   accounts.DerivationPath
+}
+
+// NewHDKey creates the master key of a mnemonic.
+func NewHDKey(mnemonic string, opts ...MnemonicOpt) (*HDKey, error) {
+	o := mnemonicOptions{path: DefaultDerivationPath}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if mnemonic == "" {
+		return nil, fmt.Errorf("mnemonic is required: %w", ErrInvalidMnemonic)
+	}
+
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, fmt.Errorf("invalid mnemonic: %w", ErrInvalidMnemonic)
+	}
+
+	path, err := parseDerivationPath(o.path)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := bip39.NewSeed(mnemonic, o.passphrase)
+
+	master, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate master key %w: %w", err, ErrInvalidMnemonic)
+	}
+
+	return &HDKey{master: master, path: path}, nil
+}
+
+// Wallet derives the wallet at the path set with WithDerivationPath.
+func (k *HDKey) Wallet() (*Wallet, error) {
+	return k.derive(k.path)
+}
+
+// Derive derives the wallet at the derivation path.
+func (k *HDKey) Derive(path string) (*Wallet, error) {
+	p, err := parseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return k.derive(p)
+}
+
+// Accounts derives count wallets by replacing the last component of the derivation
+// path with start, start+1 and so on, keeping whether it is hardened.
+// The parent key is derived only once.
+func (k *HDKey) Accounts(start, count uint32) ([]*Wallet, error) {
+	if count > math.MaxUint32-start {
+		return nil, fmt.Errorf("index range %d+%d overflows: %w", start, count, ErrInvalidDerivationPath)
+	}
+
+	if start+count > bip32.FirstHardenedChild {
+		return nil, fmt.Errorf("index range %d+%d out of range: %w", start, count, ErrInvalidDerivationPath)
+	}
+
+	hardened := k.path[len(k.path)-1] & bip32.FirstHardenedChild
+
+	parentPath := k.path[:len(k.path)-1]
+	parent, err := deriveKey(k.master, parentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	wallets := make([]*Wallet, 0, count)
+	for i := range count {
+		path := append(append(accounts.DerivationPath{}, parentPath...), hardened|(start+i))
+
+		key, err := parent.NewChildKey(path[len(path)-1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive %s %w: %w", path, err, ErrInvalidDerivationPath)
+		}
+
+		w, err := walletFromKey(key, path)
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, w)
+	}
+
+	return wallets, nil
+}
+
+// derive derives the wallet at the path.
+func (k *HDKey) derive(path accounts.DerivationPath) (*Wallet, error) {
+	key, err := deriveKey(k.master, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return walletFromKey(key, path)
+}
+
+// DerivationPath returns the derivation path of a wallet created from a
+// mnemonic, or an empty string for other wallets.
+func (w Wallet) DerivationPath() string {
+	if w.path == nil {
+		return ""
+	}
+
+	return w.path.String()
+}
+
+// parseDerivationPath parses and validates a derivation path.
+func parseDerivationPath(path string) (accounts.DerivationPath, error) {
+	p, err := accounts.ParseDerivationPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("%q %w: %w", path, err, ErrInvalidDerivationPath)
+	}
+
+	return p, nil
+}
+
+// deriveKey derives the child key at the path from the key.
+func deriveKey(key *bip32.Key, path accounts.DerivationPath) (*bip32.Key, error) {
+	for _, index := range path {
+		child, err := key.NewChildKey(index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive %s %w: %w", path, err, ErrInvalidDerivationPath)
+		}
+		key = child
+	}
+
+	return key, nil
+}
+
+// walletFromKey creates a wallet from a derived key.
+func walletFromKey(key *bip32.Key, path accounts.DerivationPath) (*Wallet, error) {
+	privateKey, err := crypto.ToECDSA(key.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert to ECDSA %w: %w", err, ErrInvalidPrivateKey)
+	}
+
+	return &Wallet{privateKey: privateKey, path: path}, nil
+}
@@ -0,0 +1,86 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestWithMnemonic(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []MnemonicOpt
+		wantAddr string
+		wantPath string
+		wantErr  error
+	}{
+		{name: "Default path", wantAddr: "0x9858EfFD232B4033E47d90003D41EC34EcaEda94", wantPath: DefaultDerivationPath},
+		{name: "Second account", opts: []MnemonicOpt{WithDerivationPath("m/44'/60'/0'/0/1")}, wantAddr: "0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0", wantPath: "m/44'/60'/0'/0/1"},
+		{name: "Relative path", opts: []MnemonicOpt{WithDerivationPath("1")}, wantAddr: "0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0", wantPath: "m/44'/60'/0'/0/1"},
+		{name: "Invalid path", opts: []MnemonicOpt{WithDerivationPath("m/44'/x")}, wantErr: ErrInvalidDerivationPath},
+		{name: "Ambiguous path", opts: []MnemonicOpt{WithDerivationPath("/44'/60'")}, wantErr: ErrInvalidDerivationPath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := New(WithMnemonic(testMnemonic, tt.opts...))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := w.Address(); got != tt.wantAddr {
+				t.Errorf("Address() = %s, want %s", got, tt.wantAddr)
+			}
+			if got := w.DerivationPath(); got != tt.wantPath {
+				t.Errorf("DerivationPath() = %s, want %s", got, tt.wantPath)
+			}
+		})
+	}
+
+	plain, err := New(WithMnemonic(testMnemonic))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	protected, err := New(WithMnemonic(testMnemonic, WithPassphrase("TREZOR")))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if plain.Address() == protected.Address() {
+		t.Errorf("WithPassphrase() derived the same address %s", plain.Address())
+	}
+}
+
+func TestHDKey_Accounts(t *testing.T) {
+	key, err := NewHDKey(testMnemonic)
+	if err != nil {
+		t.Fatalf("NewHDKey() error = %v", err)
+	}
+
+	wallets, err := key.Accounts(2, 3)
+	if err != nil {
+		t.Fatalf("Accounts() error = %v", err)
+	}
+	if len(wallets) != 3 {
+		t.Fatalf("len(Accounts()) = %d, want 3", len(wallets))
+	}
+
+	for i, w := range wallets {
+		path := fmt.Sprintf("m/44'/60'/0'/0/%d", i+2)
+
+		want, err := key.Derive(path)
+		if err != nil {
+			t.Fatalf("Derive(%s) error = %v", path, err)
+		}
+		if w.Address() != want.Address() || w.DerivationPath() != path {
+			t.Errorf("Accounts()[%d] = %s at %s, want %s at %s", i, w.Address(), w.DerivationPath(), want.Address(), path)
+		}
+	}
+
+	if _, err := key.Accounts(1<<31-1, 2); !errors.Is(err, ErrInvalidDerivationPath) {
+		t.Errorf("Accounts() error = %v, want %v", err, ErrInvalidDerivationPath)
+	}
+}
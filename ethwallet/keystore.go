@@ -0,0 +1,403 @@
+package eth
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidPassphrase is returned when a keystore file cannot be decrypted with the passphrase.
+	ErrInvalidPassphrase = errors.New("invalid passphrase")
+	// ErrInvalidKeystore is returned when a keystore file is malformed.
+	ErrInvalidKeystore = errors.New("invalid keystore")
+	// ErrWalletNotFound is returned when the keystore holds no wallet with the address.
+	ErrWalletNotFound = errors.New("wallet not found")
+	// ErrWalletExists is returned when the keystore already holds a wallet with the address.
+	ErrWalletExists = errors.New("wallet already exists")
+	// ErrKDFParams is returned when the key derivation parameters are too weak, or too costly to decrypt.
+	ErrKDFParams = errors.New("invalid key derivation parameters")
+)
+
+const (
+	// MinScryptN is the lowest scrypt CPU/memory cost accepted by WithScrypt,
+	// the cost geth uses for its light keystores
+	MinScryptN = keystore.LightScryptN
+	// MaxScryptCost is the highest scrypt cost n * p accepted by WithScrypt
+	// and from keystore files, the cost geth uses for its standard keystores
+	MaxScryptCost = keystore.StandardScryptN * keystore.StandardScryptP
+	// maxScryptR is the highest scrypt block size accepted from keystore files
+	maxScryptR = 8
+	// maxPBKDF2Iterations is the highest PBKDF2 iteration count accepted from keystore files
+	maxPBKDF2Iterations = 10_000_000
+	// derivedKeySize is the size of the derived key, half of it encrypts and half authenticates
+	derivedKeySize = 32
+	// keystoreFileMode is the permission of keystore files
+	keystoreFileMode = 0o600
+	// keystoreDirMode is the permission of keystore directories
+	keystoreDirMode = 0o700
+)
+
+// keystoreOptions holds the settings used to encrypt keystore files.
+type keystoreOptions struct {
+	scryptN int
+	scryptP int
+}
+
+// KeystoreOpt is a functional option for ExportKeystore and OpenKeystore.
+type KeystoreOpt func(*keystoreOptions) error
+
+// WithScrypt encrypts with the scrypt cost parameters n and p. n must be a
+// power of two of at least MinScryptN, p at least 1 and n * p at most
+// MaxScryptCost. The default is keystore.StandardScryptN and
+// keystore.StandardScryptP.
+func WithScrypt(n, p int) KeystoreOpt {
+	return func(o *keystoreOptions) error {
+		if n < MinScryptN || n&(n-1) != 0 {
+			return fmt.Errorf("scrypt n %d, want a power of two of at least %d: %w", n, MinScryptN, ErrKDFParams)
+		}
+
+		if p < 1 || p > MaxScryptCost/n {
+			return fmt.Errorf("scrypt p %d, want 1 to %d: %w", p, MaxScryptCost/n, ErrKDFParams)
+		}
+
+		o.scryptN = n
+		o.scryptP = p
+
+		return nil
+	}
+}
+
+// newKeystoreOptions applies the keystore options over the defaults.
+func newKeystoreOptions(opts []KeystoreOpt) (keystoreOptions, error) {
+	o := keystoreOptions{
+		scryptN: keystore.StandardScryptN,
+		scryptP: keystore.StandardScryptP,
+	}
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return o, err
+		}
+	}
+
+	return o, nil
+}
+
+// WithKeystoreJSON creates a wallet from a Web3 Secret Storage file encrypted
+// with scrypt or PBKDF2, as written by geth, MetaMask or ExportKeystore.
+// Files with a scrypt cost above MaxScryptCost or a PBKDF2 iteration count
+// above 10,000,000 are rejected with ErrKDFParams before any key is derived.
+func WithKeystoreJSON(keyJSON []byte, passphrase string) WalletOpt {
+	return func(w *Wallet) error {
+		var header struct {
+			Address string `json:"address"`
+			Crypto  struct {
+				KDF       string         `json:"kdf"`
+				KDFParams map[string]any `json:"kdfparams"`
+			} `json:"crypto"`
+		}
+		if err := json.Unmarshal(keyJSON, &header); err != nil {
+			return fmt.Errorf("failed to parse keystore %w: %w", err, ErrInvalidKeystore)
+		}
+
+		if err := checkKDFParams(header.Crypto.KDF, header.Crypto.KDFParams); err != nil {
+			return err
+		}
+
+		key, err := keystore.DecryptKey(keyJSON, passphrase)
+		if errors.Is(err, keystore.ErrDecrypt) {
+			return ErrInvalidPassphrase
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decrypt keystore %w: %w", err, ErrInvalidKeystore)
+		}
+
+		if header.Address != "" && common.HexToAddress(header.Address) != key.Address {
+			return fmt.Errorf("keystore address %s does not match key: %w", header.Address, ErrInvalidKeystore)
+		}
+
+		w.privateKey = key.PrivateKey
+
+		return nil
+	}
+}
+
+// checkKDFParams bounds the key derivation parameters of a keystore file, so
+// that a crafted file can not exhaust memory or CPU. It also makes sure every
+// parameter has the type keystore.DecryptKey expects, as it panics otherwise.
+func checkKDFParams(kdf string, params map[string]any) error {
+	if _, ok := params["salt"].(string); !ok {
+		return fmt.Errorf("kdf salt: %w", ErrInvalidKeystore)
+	}
+
+	if dklen, err := kdfParam(params, "dklen", derivedKeySize); err != nil {
+		return err
+	} else if dklen != derivedKeySize {
+		return fmt.Errorf("kdf dklen %d, want %d: %w", dklen, derivedKeySize, ErrKDFParams)
+	}
+
+	switch kdf {
+	case "scrypt":
+		n, err := kdfParam(params, "n", MaxScryptCost)
+		if err != nil {
+			return err
+		}
+
+		if _, err := kdfParam(params, "r", maxScryptR); err != nil {
+			return err
+		}
+
+		if _, err := kdfParam(params, "p", MaxScryptCost/n); err != nil {
+			return err
+		}
+	case "pbkdf2":
+		if _, ok := params["prf"].(string); !ok {
+			return fmt.Errorf("kdf prf: %w", ErrInvalidKeystore)
+		}
+
+		if _, err := kdfParam(params, "c", maxPBKDF2Iterations); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("kdf %q: %w", kdf, ErrInvalidKeystore)
+	}
+
+	return nil
+}
+
+// kdfParam returns the named integer parameter, which must be between 1 and max.
+func kdfParam(params map[string]any, name string, maxValue int) (int, error) {
+	v, ok := params[name].(float64)
+	if !ok || v != float64(int64(v)) {
+		return 0, fmt.Errorf("kdf %s: %w", name, ErrInvalidKeystore)
+	}
+
+	if v < 1 || v > float64(maxValue) {
+		return 0, fmt.Errorf("kdf %s %v, want 1 to %d: %w", name, v, maxValue, ErrKDFParams)
+	}
+
+	return int(v), nil
+}
+
+// ExportKeystore encrypts the private key of the wallet with the passphrase
+// as a scrypt Web3 Secret Storage version 3 file, as written by geth.
+func (w Wallet) ExportKeystore(passphrase string, opts ...KeystoreOpt) ([]byte, error) {
+	o, err := newKeystoreOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create keystore id: %w", err)
+	}
+
+	key := &keystore.Key{
+		Id:         id,
+		Address:    crypto.PubkeyToAddress(w.privateKey.PublicKey),
+		PrivateKey: w.privateKey,
+	}
+
+	b, err := keystore.EncryptKey(key, passphrase, o.scryptN, o.scryptP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt keystore: %w", err)
+	}
+
+	return b, nil
+}
+
+// Keystore is a directory of Web3 Secret Storage files, one per wallet,
+// named like the files geth writes.
+type Keystore struct {
+	dir  string
+	opts []KeystoreOpt
+}
+
+// OpenKeystore opens the keystore directory, creating it when it does not exist.
+// The options are used to encrypt wallets added with Store.
+func OpenKeystore(dir string, opts ...KeystoreOpt) (*Keystore, error) {
+	if _, err := newKeystoreOptions(opts); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(dir, keystoreDirMode); err != nil {
+		return nil, fmt.Errorf("failed to create keystore directory: %w", err)
+	}
+
+	return &Keystore{dir: dir, opts: opts}, nil
+}
+
+// Addresses returns the sorted checksummed addresses of the wallets in the keystore.
+// Files that are not keystore files are skipped.
+func (k *Keystore) Addresses() ([]string, error) {
+	files, err := k.files()
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]string, 0, len(files))
+	for address := range files {
+		addresses = append(addresses, address.Hex())
+	}
+	sort.Strings(addresses)
+
+	return addresses, nil
+}
+
+// Store encrypts the wallet with the passphrase and writes it to the keystore,
+// returning the path of the new file.
+func (k *Keystore) Store(w *Wallet, passphrase string) (string, error) {
+	address := crypto.PubkeyToAddress(w.privateKey.PublicKey)
+
+	if _, err := k.find(address.Hex()); err == nil {
+		return "", fmt.Errorf("%s: %w", address.Hex(), ErrWalletExists)
+	} else if !errors.Is(err, ErrWalletNotFound) {
+		return "", err
+	}
+
+	b, err := w.ExportKeystore(passphrase, k.opts...)
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("UTC--%s--%s", time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z"), hex.EncodeToString(address[:]))
+	path := filepath.Join(k.dir, name)
+
+	if err := writeFileAtomic(path, b); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// Open decrypts the wallet with the address.
+func (k *Keystore) Open(address, passphrase string) (*Wallet, error) {
+	path, err := k.find(address)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file: %w", err)
+	}
+
+	return New(WithKeystoreJSON(b, passphrase))
+}
+
+// Delete removes the wallet with the address after checking the passphrase decrypts it.
+func (k *Keystore) Delete(address, passphrase string) error {
+	if _, err := k.Open(address, passphrase); err != nil {
+		return err
+	}
+
+	path, err := k.find(address)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove keystore file: %w", err)
+	}
+
+	return nil
+}
+
+// find returns the path of the keystore file of the address.
+func (k *Keystore) find(address string) (string, error) {
+	if !common.IsHexAddress(address) {
+		return "", fmt.Errorf("%q: %w", address, ErrInvalidAddress)
+	}
+
+	files, err := k.files()
+	if err != nil {
+		return "", err
+	}
+
+	path, ok := files[common.HexToAddress(address)]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", address, ErrWalletNotFound)
+	}
+
+	return path, nil
+}
+
+// files maps the address field of every keystore file in the directory to its path.
+func (k *Keystore) files() (map[common.Address]string, error) {
+	entries, err := os.ReadDir(k.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore directory: %w", err)
+	}
+
+	files := map[common.Address]string{}
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(k.dir, entry.Name())
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read keystore file: %w", err)
+		}
+
+		var header struct {
+			Address string `json:"address"`
+		}
+		if err := json.Unmarshal(b, &header); err != nil || !common.IsHexAddress(header.Address) {
+			continue
+		}
+		files[common.HexToAddress(header.Address)] = path
+	}
+
+	return files, nil
+}
+
+// writeFileAtomic writes the file through a synced temporary file in the same directory.
+func writeFileAtomic(path string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create keystore file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	if err := tmp.Chmod(keystoreFileMode); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("failed to create keystore file: %w", err)
+	}
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("failed to write keystore file: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("failed to write keystore file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write keystore file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write keystore file: %w", err)
+	}
+
+	return nil
+}
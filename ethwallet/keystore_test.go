@@ -0,0 +1,249 @@
+package eth
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// pbkdf2TestVector is the PBKDF2 test vector of the Web3 Secret Storage definition
+const pbkdf2TestVector = `{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"6087dab2f9fdbbfaddc31a909735c1e6"},` +
+	`"ciphertext":"5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46","kdf":"pbkdf2",` +
+	`"kdfparams":{"c":262144,"dklen":32,"prf":"hmac-sha256","salt":"ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},` +
+	`"mac":"517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`
+
+func TestWithKeystoreJSON(t *testing.T) {
+	w, err := New(WithKeystoreJSON([]byte(pbkdf2TestVector), "testpassword"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if got, want := w.PrivateKeyStr(), "0x7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d"; got != want {
+		t.Errorf("PrivateKeyStr() = %s, want %s", got, want)
+	}
+
+	if _, err := New(WithKeystoreJSON([]byte(pbkdf2TestVector), "wrong")); !errors.Is(err, ErrInvalidPassphrase) {
+		t.Errorf("New() error = %v, want %v", err, ErrInvalidPassphrase)
+	}
+}
+
+func TestWithKeystoreJSON_KDFParams(t *testing.T) {
+	w, err := New(WithMnemonic(testMnemonic))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	light, err := w.ExportKeystore("secret", WithScrypt(keystore.LightScryptN, keystore.LightScryptP))
+	if err != nil {
+		t.Fatalf("ExportKeystore() error = %v", err)
+	}
+
+	// withParam returns the keystore file with a kdf parameter replaced, or removed when v is nil
+	withParam := func(keyJSON string, name string, v any) []byte {
+		t.Helper()
+
+		var file map[string]any
+		if err := json.Unmarshal([]byte(keyJSON), &file); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+
+		params := file["crypto"].(map[string]any)["kdfparams"].(map[string]any) //nolint:forcetypeassert
+		if v == nil {
+			delete(params, name)
+		} else {
+			params[name] = v
+		}
+
+		b, err := json.Marshal(file)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+
+		return b
+	}
+
+	tests := []struct {
+		name    string
+		keyJSON []byte
+		wantErr error
+	}{
+		{name: "Scrypt n", keyJSON: withParam(string(light), "n", 1<<30), wantErr: ErrKDFParams},
+		{name: "Scrypt r", keyJSON: withParam(string(light), "r", 1<<20), wantErr: ErrKDFParams},
+		{name: "Scrypt p", keyJSON: withParam(string(light), "p", 1<<20), wantErr: ErrKDFParams},
+		{name: "Scrypt dklen", keyJSON: withParam(string(light), "dklen", 1<<30), wantErr: ErrKDFParams},
+		{name: "Scrypt zero n", keyJSON: withParam(string(light), "n", 0), wantErr: ErrKDFParams},
+		{name: "Scrypt missing n", keyJSON: withParam(string(light), "n", nil), wantErr: ErrInvalidKeystore},
+		{name: "Scrypt n not a number", keyJSON: withParam(string(light), "n", "4096"), wantErr: ErrInvalidKeystore},
+		{name: "Scrypt salt not a string", keyJSON: withParam(string(light), "salt", 1), wantErr: ErrInvalidKeystore},
+		{name: "PBKDF2 c", keyJSON: withParam(pbkdf2TestVector, "c", 1<<40), wantErr: ErrKDFParams},
+		{name: "PBKDF2 missing prf", keyJSON: withParam(pbkdf2TestVector, "prf", nil), wantErr: ErrInvalidKeystore},
+		{name: "PBKDF2 short dklen", keyJSON: withParam(pbkdf2TestVector, "dklen", 16), wantErr: ErrKDFParams},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(WithKeystoreJSON(tt.keyJSON, "secret")); !errors.Is(err, tt.wantErr) {
+				t.Errorf("New() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWallet_ExportKeystore(t *testing.T) {
+	w, err := New(WithMnemonic(testMnemonic))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts []KeystoreOpt
+	}{
+		{name: "Scrypt", opts: []KeystoreOpt{WithScrypt(keystore.LightScryptN, keystore.LightScryptP)}},
+		{name: "Default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := w.ExportKeystore("secret", tt.opts...)
+			if err != nil {
+				t.Fatalf("ExportKeystore() error = %v", err)
+			}
+
+			key, err := keystore.DecryptKey(b, "secret")
+			if err != nil {
+				t.Fatalf("keystore.DecryptKey() error = %v", err)
+			}
+			if got := hexutil.Encode(crypto.FromECDSA(key.PrivateKey)); got != w.PrivateKeyStr() {
+				t.Errorf("keystore.DecryptKey() = %s, want %s", got, w.PrivateKeyStr())
+			}
+
+			got, err := New(WithKeystoreJSON(b, "secret"))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if got.Address() != w.Address() {
+				t.Errorf("Address() = %s, want %s", got.Address(), w.Address())
+			}
+		})
+	}
+}
+
+func TestWithScrypt(t *testing.T) {
+	w, err := New(WithMnemonic(testMnemonic))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		n       int
+		p       int
+		wantErr bool
+	}{
+		{name: "Light", n: keystore.LightScryptN, p: keystore.LightScryptP},
+		{name: "Standard", n: keystore.StandardScryptN, p: keystore.StandardScryptP},
+		{name: "Low n", n: 1024, p: 1, wantErr: true},
+		{name: "n not a power of two", n: MinScryptN + 1, p: 1, wantErr: true},
+		{name: "Negative n", n: -MinScryptN, p: 1, wantErr: true},
+		{name: "Zero p", n: MinScryptN, p: 0, wantErr: true},
+		{name: "Above max cost", n: keystore.StandardScryptN, p: 2, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.name == "Standard" && testing.Short() {
+				t.Skip("standard scrypt cost in short mode")
+			}
+
+			_, err := w.ExportKeystore("secret", WithScrypt(tt.n, tt.p))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExportKeystore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrKDFParams) {
+				t.Errorf("ExportKeystore() error = %v, want %v", err, ErrKDFParams)
+			}
+			if _, err := OpenKeystore(t.TempDir(), WithScrypt(tt.n, tt.p)); (err != nil) != tt.wantErr {
+				t.Errorf("OpenKeystore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestKeystore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keystore")
+
+	ks, err := OpenKeystore(dir, WithScrypt(keystore.LightScryptN, keystore.LightScryptP))
+	if err != nil {
+		t.Fatalf("OpenKeystore() error = %v", err)
+	}
+
+	key, err := NewHDKey(testMnemonic)
+	if err != nil {
+		t.Fatalf("NewHDKey() error = %v", err)
+	}
+	wallets, err := key.Accounts(0, 2)
+	if err != nil {
+		t.Fatalf("Accounts() error = %v", err)
+	}
+
+	for _, w := range wallets {
+		path, err := ks.Store(w, "secret")
+		if err != nil {
+			t.Fatalf("Store() error = %v", err)
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+		if perm := info.Mode().Perm(); perm != keystoreFileMode {
+			t.Errorf("Store() file mode = %o, want %o", perm, keystoreFileMode)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not a key"), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	got, err := ks.Addresses()
+	if err != nil {
+		t.Fatalf("Addresses() error = %v", err)
+	}
+	want := []string{"0x6Fac4D18c912343BF86fa7049364Dd4E424Ab9C0", "0x9858EfFD232B4033E47d90003D41EC34EcaEda94"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Addresses() = %v, want %v", got, want)
+	}
+
+	if _, err := ks.Store(wallets[0], "secret"); !errors.Is(err, ErrWalletExists) {
+		t.Errorf("Store() error = %v, want %v", err, ErrWalletExists)
+	}
+
+	opened, err := ks.Open("0x9858effd232b4033e47d90003d41ec34ecaeda94", "secret")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if opened.Address() != wallets[0].Address() {
+		t.Errorf("Open() address = %s, want %s", opened.Address(), wallets[0].Address())
+	}
+
+	if err := ks.Delete(want[0], "wrong"); !errors.Is(err, ErrInvalidPassphrase) {
+		t.Errorf("Delete() error = %v, want %v", err, ErrInvalidPassphrase)
+	}
+	if err := ks.Delete(want[0], "secret"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := ks.Open(want[0], "secret"); !errors.Is(err, ErrWalletNotFound) {
+		t.Errorf("Open() error = %v, want %v", err, ErrWalletNotFound)
+	}
+	if _, err := ks.Open("not an address", "secret"); !errors.Is(err, ErrInvalidAddress) {
+		t.Errorf("Open() error = %v, want %v", err, ErrInvalidAddress)
+	}
+}
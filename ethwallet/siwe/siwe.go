@@ -0,0 +1,321 @@
+// Package siwe builds, parses and verifies Sign-In with Ethereum (EIP-4361) messages.
+package siwe
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	// ErrInvalidMessage is returned when a message is malformed or misses a required field.
+	ErrInvalidMessage = errors.New("invalid siwe message")
+	// ErrExpired is returned when a message is used after its expiration time.
+	ErrExpired = errors.New("siwe message expired")
+	// ErrNotYetValid is returned when a message is used before its not before time.
+	ErrNotYetValid = errors.New("siwe message not yet valid")
+	// ErrDomainMismatch is returned when a message was made for another domain.
+	ErrDomainMismatch = errors.New("siwe domain mismatch")
+)
+
+const (
+	// Version is the only message version defined by EIP-4361
+	Version = "1"
+	// minNonceLength is the minimum nonce length required by EIP-4361
+	minNonceLength = 8
+
+	preambleSuffix = " wants you to sign in with your Ethereum account:"
+	tagURI         = "URI: "
+	tagVersion     = "Version: "
+	tagChainID     = "Chain ID: "
+	tagNonce       = "Nonce: "
+	tagIssuedAt    = "Issued At: "
+	tagExpiration  = "Expiration Time: "
+	tagNotBefore   = "Not Before: "
+	tagRequestID   = "Request ID: "
+	tagResources   = "Resources:"
+	resourcePrefix = "- "
+)
+
+// Message is a Sign-In with Ethereum message.
+type Message struct {
+	// Scheme is the optional URI scheme of the origin, such as "https"
+	Scheme string
+	// Domain is the RFC 3986 authority requesting the signing
+	Domain string
+	// Address is the EIP-55 checksummed address signing in
+	Address string
+	// Statement is an optional single line shown to the user
+	Statement string
+	// URI is the resource that is the subject of the signing
+	URI     string
+	Version string
+	ChainID int64
+	Nonce   string
+	// IssuedAt is required, ExpirationTime and NotBefore are omitted when zero
+	IssuedAt       time.Time
+	ExpirationTime time.Time
+	NotBefore      time.Time
+	RequestID      string
+	Resources      []string
+}
+
+// Validate checks that the required fields are set and well formed.
+func (m Message) Validate() error {
+	switch {
+	case m.Domain == "" || strings.ContainsAny(m.Domain, " \n/"):
+		return fmt.Errorf("domain %q: %w", m.Domain, ErrInvalidMessage)
+	case !common.IsHexAddress(m.Address) || common.HexToAddress(m.Address).Hex() != m.Address:
+		return fmt.Errorf("address %q is not EIP-55 checksummed: %w", m.Address, ErrInvalidMessage)
+	case strings.Contains(m.Statement, "\n"):
+		return fmt.Errorf("statement spans lines: %w", ErrInvalidMessage)
+	case m.Version != Version:
+		return fmt.Errorf("version %q: %w", m.Version, ErrInvalidMessage)
+	case m.ChainID <= 0:
+		return fmt.Errorf("chain id %d: %w", m.ChainID, ErrInvalidMessage)
+	case len(m.Nonce) < minNonceLength || !isAlphanumeric(m.Nonce):
+		return fmt.Errorf("nonce %q: %w", m.Nonce, ErrInvalidMessage)
+	case m.IssuedAt.IsZero():
+		return fmt.Errorf("issued at is required: %w", ErrInvalidMessage)
+	case strings.Contains(m.RequestID, "\n"):
+		return fmt.Errorf("request id spans lines: %w", ErrInvalidMessage)
+	}
+
+	for _, uri := range append([]string{m.URI}, m.Resources...) {
+		if u, err := url.Parse(uri); err != nil || u.Scheme == "" || strings.ContainsAny(uri, " \n") {
+			return fmt.Errorf("uri %q: %w", uri, ErrInvalidMessage)
+		}
+	}
+
+	return nil
+}
+
+// String returns the message text that is signed with personal_sign.
+func (m Message) String() string {
+	var b strings.Builder
+
+	if m.Scheme != "" {
+		b.WriteString(m.Scheme + "://")
+	}
+	b.WriteString(m.Domain + preambleSuffix + "\n")
+	b.WriteString(m.Address + "\n\n")
+	if m.Statement != "" {
+		b.WriteString(m.Statement + "\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString(tagURI + m.URI + "\n")
+	b.WriteString(tagVersion + m.Version + "\n")
+	b.WriteString(tagChainID + strconv.FormatInt(m.ChainID, 10) + "\n")
+	b.WriteString(tagNonce + m.Nonce + "\n")
+	b.WriteString(tagIssuedAt + m.IssuedAt.Format(time.RFC3339Nano))
+
+	if !m.ExpirationTime.IsZero() {
+		b.WriteString("\n" + tagExpiration + m.ExpirationTime.Format(time.RFC3339Nano))
+	}
+	if !m.NotBefore.IsZero() {
+		b.WriteString("\n" + tagNotBefore + m.NotBefore.Format(time.RFC3339Nano))
+	}
+	if m.RequestID != "" {
+		b.WriteString("\n" + tagRequestID + m.RequestID)
+	}
+	if len(m.Resources) > 0 {
+		b.WriteString("\n" + tagResources)
+		for _, r := range m.Resources {
+			b.WriteString("\n" + resourcePrefix + r)
+		}
+	}
+
+	return b.String()
+}
+
+// ValidAt checks the expiration and not before times of the message against t.
+func (m Message) ValidAt(t time.Time) error {
+	if !m.ExpirationTime.IsZero() && !t.Before(m.ExpirationTime) {
+		return fmt.Errorf("expired at %s: %w", m.ExpirationTime.Format(time.RFC3339), ErrExpired)
+	}
+
+	if !m.NotBefore.IsZero() && t.Before(m.NotBefore) {
+		return fmt.Errorf("valid from %s: %w", m.NotBefore.Format(time.RFC3339), ErrNotYetValid)
+	}
+
+	return nil
+}
+
+// DID returns the did:pkh identifier of the signing account, such as
+// did:pkh:eip155:1:0xb9c5714089478a327f09197987f16f9e5d936e8a.
+func (m Message) DID() string {
+	return fmt.Sprintf("did:pkh:eip155:%d:%s", m.ChainID, m.Address)
+}
+
+// ParseMessage parses and validates the text of a Sign-In with Ethereum message.
+func ParseMessage(text string) (*Message, error) {
+	p := &parser{lines: strings.Split(text, "\n")}
+	var m Message
+
+	origin, ok := strings.CutSuffix(p.next(), preambleSuffix)
+	if !ok {
+		return nil, fmt.Errorf("missing preamble: %w", ErrInvalidMessage)
+	}
+	if scheme, domain, ok := strings.Cut(origin, "://"); ok {
+		m.Scheme, m.Domain = scheme, domain
+	} else {
+		m.Domain = origin
+	}
+
+	m.Address = p.next()
+	if p.next() != "" {
+		return nil, fmt.Errorf("missing blank line after address: %w", ErrInvalidMessage)
+	}
+
+	// The statement is optional, older messages omit the blank line that follows it
+	switch line := p.peek(); {
+	case line == "":
+		p.next()
+		if !strings.HasPrefix(p.peek(), tagURI) {
+			m.Statement = p.next()
+			if p.next() != "" {
+				return nil, fmt.Errorf("missing blank line after statement: %w", ErrInvalidMessage)
+			}
+		}
+	case !strings.HasPrefix(line, tagURI):
+		m.Statement = p.next()
+		if p.next() != "" {
+			return nil, fmt.Errorf("missing blank line after statement: %w", ErrInvalidMessage)
+		}
+	}
+
+	var err error
+	var chainID, issuedAt string
+	if m.URI, err = p.field(tagURI, true); err != nil {
+		return nil, err
+	}
+	if m.Version, err = p.field(tagVersion, true); err != nil {
+		return nil, err
+	}
+	if chainID, err = p.field(tagChainID, true); err != nil {
+		return nil, err
+	}
+	if m.ChainID, err = strconv.ParseInt(chainID, 10, 64); err != nil {
+		return nil, fmt.Errorf("chain id %q: %w", chainID, ErrInvalidMessage)
+	}
+	if m.Nonce, err = p.field(tagNonce, true); err != nil {
+		return nil, err
+	}
+	if issuedAt, err = p.field(tagIssuedAt, true); err != nil {
+		return nil, err
+	}
+	if m.IssuedAt, err = parseTime(issuedAt); err != nil {
+		return nil, err
+	}
+	if m.ExpirationTime, err = p.timeField(tagExpiration); err != nil {
+		return nil, err
+	}
+	if m.NotBefore, err = p.timeField(tagNotBefore); err != nil {
+		return nil, err
+	}
+	if m.RequestID, err = p.field(tagRequestID, false); err != nil {
+		return nil, err
+	}
+
+	if p.peek() == tagResources {
+		p.next()
+		for p.more() {
+			resource, ok := strings.CutPrefix(p.next(), resourcePrefix)
+			if !ok {
+				return nil, fmt.Errorf("malformed resource: %w", ErrInvalidMessage)
+			}
+			m.Resources = append(m.Resources, resource)
+		}
+	}
+
+	if p.more() {
+		return nil, fmt.Errorf("unexpected line %q: %w", p.peek(), ErrInvalidMessage)
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// parser walks the lines of a message.
+type parser struct {
+	lines []string
+	pos   int
+}
+
+// more reports whether lines remain.
+func (p *parser) more() bool {
+	return p.pos < len(p.lines)
+}
+
+// peek returns the current line without consuming it, or "" at the end.
+func (p *parser) peek() string {
+	if !p.more() {
+		return ""
+	}
+
+	return p.lines[p.pos]
+}
+
+// next consumes and returns the current line, or "" at the end.
+func (p *parser) next() string {
+	line := p.peek()
+	p.pos++
+
+	return line
+}
+
+// field consumes the line starting with tag and returns its value. Optional
+// fields that are absent return an empty value.
+func (p *parser) field(tag string, required bool) (string, error) {
+	value, ok := strings.CutPrefix(p.peek(), tag)
+	if !ok || !p.more() {
+		if required {
+			return "", fmt.Errorf("missing %q: %w", strings.TrimSpace(tag), ErrInvalidMessage)
+		}
+
+		return "", nil
+	}
+	p.next()
+
+	return value, nil
+}
+
+// timeField consumes an optional RFC 3339 time field.
+func (p *parser) timeField(tag string) (time.Time, error) {
+	value, err := p.field(tag, false)
+	if err != nil || value == "" {
+		return time.Time{}, err
+	}
+
+	return parseTime(value)
+}
+
+// parseTime parses an RFC 3339 date-time.
+func parseTime(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("time %q: %w", value, ErrInvalidMessage)
+	}
+
+	return t, nil
+}
+
+// isAlphanumeric reports whether s only holds ASCII letters and digits.
+func isAlphanumeric(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+			return false
+		}
+	}
+
+	return true
+}
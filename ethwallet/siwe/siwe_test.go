@@ -0,0 +1,171 @@
+package siwe
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	eth "github.com/euforic/pkg-go/ethwallet"
+)
+
+// exampleMessage is the example message of EIP-4361
+const exampleMessage = `service.invalid wants you to sign in with your Ethereum account:
+0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2
+
+I accept the ServiceOrg Terms of Service: https://service.invalid/tos
+
+URI: https://service.invalid/login
+Version: 1
+Chain ID: 1
+Nonce: 32891756
+Issued At: 2021-09-30T16:25:24Z
+Resources:
+- ipfs://bafybeiemxf5abjwjbikoz4mc3a3dla6ual3jsgpdr4cjr3oz3evfyavhwq/
+- https://example.com/my-web2-claim.json`
+
+func TestParseMessage(t *testing.T) {
+	m, err := ParseMessage(exampleMessage)
+	if err != nil {
+		t.Fatalf("ParseMessage() error = %v", err)
+	}
+	if m.Statement != "I accept the ServiceOrg Terms of Service: https://service.invalid/tos" || m.Nonce != "32891756" || len(m.Resources) != 2 {
+		t.Errorf("ParseMessage() = %+v", m)
+	}
+	if got := m.String(); got != exampleMessage {
+		t.Errorf("String() = %q, want %q", got, exampleMessage)
+	}
+
+	full := Message{
+		Scheme:         "https",
+		Domain:         "example.com:8443",
+		Address:        "0x9858EfFD232B4033E47d90003D41EC34EcaEda94",
+		URI:            "https://example.com/login",
+		Version:        Version,
+		ChainID:        10,
+		Nonce:          "abcdEFGH1234",
+		IssuedAt:       time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		ExpirationTime: time.Date(2024, 1, 2, 4, 4, 5, 0, time.UTC),
+		NotBefore:      time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC),
+		RequestID:      "request-1",
+	}
+	got, err := ParseMessage(full.String())
+	if err != nil {
+		t.Fatalf("ParseMessage() error = %v", err)
+	}
+	if got.String() != full.String() || got.Statement != "" || got.Scheme != "https" {
+		t.Errorf("ParseMessage() = %q, want %q", got.String(), full.String())
+	}
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{name: "Missing preamble", text: strings.Replace(exampleMessage, " wants you", " would like you", 1)},
+		{name: "Lowercase address", text: strings.Replace(exampleMessage, "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", "0xc02aaa39b223fe8d0a0e5c4f27ead9083c756cc2", 1)},
+		{name: "Short nonce", text: strings.Replace(exampleMessage, "Nonce: 32891756", "Nonce: 1234", 1)},
+		{name: "Missing issued at", text: strings.Replace(exampleMessage, "Issued At: 2021-09-30T16:25:24Z\n", "", 1)},
+		{name: "Bad chain id", text: strings.Replace(exampleMessage, "Chain ID: 1", "Chain ID: one", 1)},
+		{name: "Unknown version", text: strings.Replace(exampleMessage, "Version: 1", "Version: 2", 1)},
+		{name: "Trailing line", text: exampleMessage + "\nExtra: field"},
+		{name: "Fields out of order", text: strings.Replace(strings.Replace(exampleMessage, "Version: 1\n", "", 1), "Chain ID: 1\n", "Chain ID: 1\nVersion: 1\n", 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseMessage(tt.text); !errors.Is(err, ErrInvalidMessage) {
+				t.Errorf("ParseMessage() error = %v, want %v", err, ErrInvalidMessage)
+			}
+		})
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	key, err := eth.NewHDKey("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Fatalf("NewHDKey() error = %v", err)
+	}
+	wallets, err := key.Accounts(0, 2)
+	if err != nil {
+		t.Fatalf("Accounts() error = %v", err)
+	}
+	w, other := wallets[0], wallets[1]
+
+	store := NewMemoryNonceStore()
+	store.now = func() time.Time { return now }
+	v := NewVerifier("example.com", store, WithClock(func() time.Time { return now }))
+
+	sign := func(t *testing.T, signer *eth.Wallet, edit func(*Message)) (string, string) {
+		t.Helper()
+
+		nonce, err := v.Nonce(ctx)
+		if err != nil {
+			t.Fatalf("Nonce() error = %v", err)
+		}
+
+		m := Message{
+			Domain:         "example.com",
+			Address:        w.Address(),
+			Statement:      "Sign in to Example",
+			URI:            "https://example.com/login",
+			Version:        Version,
+			ChainID:        1,
+			Nonce:          nonce,
+			IssuedAt:       now,
+			ExpirationTime: now.Add(time.Minute),
+		}
+		if edit != nil {
+			edit(&m)
+		}
+
+		signature, err := signer.SignMessage(m.String())
+		if err != nil {
+			t.Fatalf("SignMessage() error = %v", err)
+		}
+
+		return m.String(), signature
+	}
+
+	text, signature := sign(t, w, nil)
+	m, err := v.Verify(ctx, text, signature)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if got, want := m.DID(), "did:pkh:eip155:1:"+w.Address(); got != want {
+		t.Errorf("DID() = %s, want %s", got, want)
+	}
+	if _, err := v.Verify(ctx, text, signature); !errors.Is(err, ErrInvalidNonce) {
+		t.Errorf("Verify(replay) error = %v, want %v", err, ErrInvalidNonce)
+	}
+
+	tests := []struct {
+		name    string
+		signer  *eth.Wallet
+		edit    func(*Message)
+		wantErr error
+	}{
+		{name: "Other signer", signer: other, wantErr: eth.ErrInvalidSignature},
+		{name: "Other domain", signer: w, edit: func(m *Message) { m.Domain = "evil.example" }, wantErr: ErrDomainMismatch},
+		{name: "Expired", signer: w, edit: func(m *Message) { m.ExpirationTime = now }, wantErr: ErrExpired},
+		{name: "Not yet valid", signer: w, edit: func(m *Message) { m.NotBefore = now.Add(time.Second) }, wantErr: ErrNotYetValid},
+		{name: "Unknown nonce", signer: w, edit: func(m *Message) { m.Nonce = "unknownNonce1" }, wantErr: ErrInvalidNonce},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, signature := sign(t, tt.signer, tt.edit)
+			if _, err := v.Verify(ctx, text, signature); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	text, signature = sign(t, w, nil)
+	if _, err := v.Verify(ctx, strings.Replace(text, "Sign in", "Log in", 1), signature); !errors.Is(err, eth.ErrInvalidSignature) {
+		t.Errorf("Verify(tampered) error = %v, want %v", err, eth.ErrInvalidSignature)
+	}
+}
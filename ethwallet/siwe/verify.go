@@ -0,0 +1,177 @@
+package siwe
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	eth "github.com/euforic/pkg-go/ethwallet"
+)
+
+// ErrInvalidNonce is returned when a nonce was not issued, has expired or was already used.
+var ErrInvalidNonce = errors.New("invalid siwe nonce")
+
+const (
+	// DefaultNonceTTL is how long a nonce issued by Verifier.Nonce may be used
+	DefaultNonceTTL = 10 * time.Minute
+	// nonceLength is the length of nonces made by NewNonce
+	nonceLength = 17
+	// nonceAlphabet are the characters of nonces made by NewNonce
+	nonceAlphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+// NonceStore records issued nonces so each can be used to sign in only once.
+type NonceStore interface {
+	// Put records a nonce that may be consumed until ttl has passed.
+	Put(ctx context.Context, nonce string, ttl time.Duration) error
+	// Consume removes the nonce, returning ErrInvalidNonce when it is unknown or expired.
+	Consume(ctx context.Context, nonce string) error
+}
+
+// MemoryNonceStore is a NonceStore for a single process.
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+	now    func() time.Time
+}
+
+// NewMemoryNonceStore creates a new, empty in-memory nonce store.
+func NewMemoryNonceStore() *MemoryNonceStore {
+	return &MemoryNonceStore{nonces: map[string]time.Time{}, now: time.Now}
+}
+
+// Put records a nonce that may be consumed until ttl has passed, dropping expired nonces.
+func (s *MemoryNonceStore) Put(_ context.Context, nonce string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for n, expires := range s.nonces {
+		if !now.Before(expires) {
+			delete(s.nonces, n)
+		}
+	}
+	s.nonces[nonce] = now.Add(ttl)
+
+	return nil
+}
+
+// Consume removes the nonce, returning ErrInvalidNonce when it is unknown or expired.
+func (s *MemoryNonceStore) Consume(_ context.Context, nonce string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expires, ok := s.nonces[nonce]
+	delete(s.nonces, nonce)
+	if !ok || !s.now().Before(expires) {
+		return ErrInvalidNonce
+	}
+
+	return nil
+}
+
+// NewNonce returns a random alphanumeric nonce.
+func NewNonce() (string, error) {
+	b := make([]byte, nonceLength)
+	limit := big.NewInt(int64(len(nonceAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate nonce: %w", err)
+		}
+		b[i] = nonceAlphabet[n.Int64()]
+	}
+
+	return string(b), nil
+}
+
+// Verifier issues nonces and verifies signed messages for one domain.
+type Verifier struct {
+	domain   string
+	nonces   NonceStore
+	nonceTTL time.Duration
+	now      func() time.Time
+}
+
+// VerifierOpt is a functional option for NewVerifier.
+type VerifierOpt func(*Verifier)
+
+// WithNonceTTL sets how long issued nonces may be used, DefaultNonceTTL by default.
+func WithNonceTTL(ttl time.Duration) VerifierOpt {
+	return func(v *Verifier) {
+		v.nonceTTL = ttl
+	}
+}
+
+// WithClock sets the clock used to check message times, time.Now by default.
+func WithClock(now func() time.Time) VerifierOpt {
+	return func(v *Verifier) {
+		v.now = now
+	}
+}
+
+// NewVerifier creates a verifier accepting messages for the domain whose nonces are in the store.
+func NewVerifier(domain string, nonces NonceStore, opts ...VerifierOpt) *Verifier {
+	v := &Verifier{
+		domain:   domain,
+		nonces:   nonces,
+		nonceTTL: DefaultNonceTTL,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	return v
+}
+
+// Nonce issues a nonce to put in the next message.
+func (v *Verifier) Nonce(ctx context.Context) (string, error) {
+	nonce, err := NewNonce()
+	if err != nil {
+		return "", err
+	}
+
+	if err := v.nonces.Put(ctx, nonce, v.nonceTTL); err != nil {
+		return "", fmt.Errorf("failed to store nonce: %w", err)
+	}
+
+	return nonce, nil
+}
+
+// Verify parses the signed message text and checks its domain, times and
+// signature before consuming its nonce. The returned message identifies the
+// account that signed in and can be exchanged for a session or token.
+func (v *Verifier) Verify(ctx context.Context, text, signatureHex string) (*Message, error) {
+	m, err := ParseMessage(text)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Domain != v.domain {
+		return nil, fmt.Errorf("message for %q: %w", m.Domain, ErrDomainMismatch)
+	}
+
+	if err := m.ValidAt(v.now()); err != nil {
+		return nil, err
+	}
+
+	signer, err := eth.SignatureAddress(signatureHex, text)
+	if err != nil {
+		return nil, fmt.Errorf("siwe: %w", err)
+	}
+
+	if signer != m.Address {
+		return nil, fmt.Errorf("signed by %s: %w", signer, eth.ErrInvalidSignature)
+	}
+
+	if err := v.nonces.Consume(ctx, m.Nonce); err != nil {
+		return nil, fmt.Errorf("nonce %s: %w", m.Nonce, err)
+	}
+
+	return m, nil
+}
@@ -0,0 +1,143 @@
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ErrInvalidAmount is returned when an amount cannot be parsed.
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// decimalAmount matches plain decimal amounts, without sign, exponent, base prefix or digit separators
+var decimalAmount = regexp.MustCompile(`^\d+(\.\d+)?$`) //nolint:gochecknoglobals
+
+// TxParams are the fields of a dynamic-fee (EIP-1559) transaction.
+type TxParams struct {
+	Nonce uint64
+	// To is the hex address of the recipient, empty for contract creation
+	To    string
+	Value *big.Int
+	Gas   uint64
+	// GasTipCap is the priority fee per gas paid to the block producer
+	GasTipCap *big.Int
+	// GasFeeCap is the maximum fee per gas, base fee included
+	GasFeeCap  *big.Int
+	Data       []byte
+	AccessList types.AccessList
+}
+
+// NewDynamicFeeTx builds an unsigned dynamic-fee transaction for the chain.
+func NewDynamicFeeTx(chainID *big.Int, p TxParams) (*types.Transaction, error) {
+	if chainID == nil || chainID.Sign() <= 0 {
+		return nil, fmt.Errorf("chain id %v: %w", chainID, types.ErrInvalidChainId)
+	}
+
+	if p.GasTipCap == nil || p.GasFeeCap == nil {
+		return nil, fmt.Errorf("gas tip cap and fee cap are required: %w", ErrInvalidAmount)
+	}
+
+	if p.GasFeeCap.Cmp(p.GasTipCap) < 0 {
+		return nil, fmt.Errorf("fee cap %s below tip cap %s: %w", p.GasFeeCap, p.GasTipCap, ErrInvalidAmount)
+	}
+
+	var to *common.Address
+	if p.To != "" {
+		if !common.IsHexAddress(p.To) {
+			return nil, fmt.Errorf("recipient %q: %w", p.To, ErrInvalidAddress)
+		}
+
+		address := common.HexToAddress(p.To)
+		to = &address
+	}
+
+	value := p.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:    new(big.Int).Set(chainID),
+		Nonce:      p.Nonce,
+		GasTipCap:  new(big.Int).Set(p.GasTipCap),
+		GasFeeCap:  new(big.Int).Set(p.GasFeeCap),
+		Gas:        p.Gas,
+		To:         to,
+		Value:      new(big.Int).Set(value),
+		Data:       p.Data,
+		AccessList: p.AccessList,
+	}), nil
+}
+
+// SignDynamicFeeTx builds and signs a dynamic-fee transaction for the chain.
+func (w Wallet) SignDynamicFeeTx(chainID *big.Int, p TxParams) (*types.Transaction, error) {
+	tx, err := NewDynamicFeeTx(chainID, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.SignTransaction(chainID, tx)
+}
+
+// RawTransaction returns the hex encoded binary form of a signed transaction,
+// as sent with eth_sendRawTransaction.
+func RawTransaction(tx *types.Transaction) (string, error) {
+	b, err := tx.MarshalBinary()
+	if err != nil {
+		return "", fmt.Errorf("failed to encode transaction: %w", err)
+	}
+
+	return hexutil.Encode(b), nil
+}
+
+// ParseEther parses a decimal ether amount such as "0.25" into wei.
+func ParseEther(amount string) (*big.Int, error) {
+	return parseUnits(amount, params.Ether)
+}
+
+// ParseGwei parses a decimal gwei amount such as "1.5" into wei.
+func ParseGwei(amount string) (*big.Int, error) {
+	return parseUnits(amount, params.GWei)
+}
+
+// signerFor returns the signer for the transaction type: the London signer for
+// legacy, access list and dynamic-fee transactions and the Cancun signer for blob transactions.
+func signerFor(chainID *big.Int, tx *types.Transaction) types.Signer { //nolint:ireturn
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType, types.DynamicFeeTxType:
+		return types.NewLondonSigner(chainID)
+	case types.BlobTxType:
+		return types.NewCancunSigner(chainID)
+	default:
+		return types.LatestSignerForChainID(chainID)
+	}
+}
+
+// parseUnits parses a non-negative decimal amount scaled by unit into an integer.
+func parseUnits(amount string, unit int64) (*big.Int, error) {
+	amount = strings.TrimSpace(amount)
+
+	// big.Rat also accepts fractions, exponents, base prefixes such as 0x and underscores
+	if !decimalAmount.MatchString(amount) {
+		return nil, fmt.Errorf("%q: %w", amount, ErrInvalidAmount)
+	}
+
+	r, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return nil, fmt.Errorf("%q: %w", amount, ErrInvalidAmount)
+	}
+
+	r.Mul(r, new(big.Rat).SetInt64(unit))
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%q has more decimals than the unit: %w", amount, ErrInvalidAmount)
+	}
+
+	return new(big.Int).Set(r.Num()), nil
+}
@@ -0,0 +1,150 @@
+package eth
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/holiman/uint256"
+)
+
+func TestWallet_SignTransaction(t *testing.T) {
+	w, err := New(WithMnemonic(testMnemonic))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	chainID := big.NewInt(11155111)
+	to := common.HexToAddress("0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB")
+
+	dynamicFee, err := NewDynamicFeeTx(chainID, TxParams{
+		Nonce:     1,
+		To:        to.Hex(),
+		Value:     big.NewInt(1),
+		Gas:       21000,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(30e9),
+	})
+	if err != nil {
+		t.Fatalf("NewDynamicFeeTx() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		tx      *types.Transaction
+		wantErr bool
+	}{
+		{name: "Legacy", tx: types.NewTransaction(0, to, big.NewInt(1), 21000, big.NewInt(1e9), nil)},
+		{name: "Access list", tx: types.NewTx(&types.AccessListTx{ChainID: chainID, Gas: 21000, GasPrice: big.NewInt(1e9), To: &to})},
+		{name: "Dynamic fee", tx: dynamicFee},
+		{name: "Blob", tx: types.NewTx(&types.BlobTx{
+			ChainID:    uint256.MustFromBig(chainID),
+			Gas:        21000,
+			GasTipCap:  uint256.NewInt(1e9),
+			GasFeeCap:  uint256.NewInt(30e9),
+			BlobFeeCap: uint256.NewInt(1e9),
+			BlobHashes: []common.Hash{{0x01}},
+			To:         to,
+		})},
+		{name: "Wrong chain", tx: types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), Gas: 21000, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(1)}), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signed, err := w.SignTransaction(chainID, tt.tx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SignTransaction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			from, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+			if err != nil {
+				t.Fatalf("Sender() error = %v", err)
+			}
+			if from.Hex() != w.Address() {
+				t.Errorf("Sender() = %s, want %s", from.Hex(), w.Address())
+			}
+			if signed.Type() != tt.tx.Type() {
+				t.Errorf("Type() = %d, want %d", signed.Type(), tt.tx.Type())
+			}
+
+			if _, err := RawTransaction(signed); err != nil {
+				t.Errorf("RawTransaction() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestNewDynamicFeeTx(t *testing.T) {
+	tests := []struct {
+		name    string
+		chainID *big.Int
+		params  TxParams
+		wantErr error
+	}{
+		{name: "Contract creation", chainID: big.NewInt(1), params: TxParams{GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Data: []byte{0x60}}},
+		{name: "Missing fee caps", chainID: big.NewInt(1), params: TxParams{}, wantErr: ErrInvalidAmount},
+		{name: "Tip above fee cap", chainID: big.NewInt(1), params: TxParams{GasTipCap: big.NewInt(2), GasFeeCap: big.NewInt(1)}, wantErr: ErrInvalidAmount},
+		{name: "Invalid recipient", chainID: big.NewInt(1), params: TxParams{To: "0x1234", GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(1)}, wantErr: ErrInvalidAddress},
+		{name: "Missing chain id", params: TxParams{GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(1)}, wantErr: types.ErrInvalidChainId},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := NewDynamicFeeTx(tt.chainID, tt.params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewDynamicFeeTx() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && (tx.Type() != types.DynamicFeeTxType || tx.To() != nil) {
+				t.Errorf("NewDynamicFeeTx() = type %d to %v, want dynamic-fee contract creation", tx.Type(), tx.To())
+			}
+		})
+	}
+}
+
+func TestParseEther(t *testing.T) {
+	tests := []struct {
+		amount  string
+		want    string
+		wantErr bool
+	}{
+		{amount: "1", want: "1000000000000000000"},
+		{amount: "0.25", want: "250000000000000000"},
+		{amount: " 2.000000000000000001 ", want: "2000000000000000001"},
+		{amount: "0.0000000000000000001", wantErr: true},
+		{amount: "-1", wantErr: true},
+		{amount: "1e18", wantErr: true},
+		{amount: "1/2", wantErr: true},
+		{amount: "ten", wantErr: true},
+		{amount: "0x10", wantErr: true},
+		{amount: "0X10", wantErr: true},
+		{amount: "0b11", wantErr: true},
+		{amount: "0o7", wantErr: true},
+		{amount: "1_000", wantErr: true},
+		{amount: "+1", wantErr: true},
+		{amount: ".5", wantErr: true},
+		{amount: "1.", wantErr: true},
+		{amount: "", wantErr: true},
+		{amount: "010", want: "10000000000000000000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.amount, func(t *testing.T) {
+			got, err := ParseEther(tt.amount)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseEther(%q) error = %v, wantErr %v", tt.amount, err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParseEther(%q) = %s, want %s", tt.amount, got, tt.want)
+			}
+		})
+	}
+
+	if got, err := ParseGwei("1.5"); err != nil || got.Int64() != 1.5e9 {
+		t.Errorf("ParseGwei(1.5) = %v, %v, want 1500000000", got, err)
+	}
+}
@@ -0,0 +1,86 @@
+package eth
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// ParseTypedData parses EIP-712 typed data in the JSON form passed to eth_signTypedData_v4.
+func ParseTypedData(b []byte) (apitypes.TypedData, error) {
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal(b, &typedData); err != nil {
+		return apitypes.TypedData{}, fmt.Errorf("failed to parse typed data: %w", err)
+	}
+
+	return typedData, nil
+}
+
+// TypedDataHash returns the EIP-712 hash of the typed data, the digest that is signed.
+func TypedDataHash(typedData apitypes.TypedData) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %w", err)
+	}
+
+	return hash, nil
+}
+
+// SignTypedData signs EIP-712 typed data, as eth_signTypedData_v4 does.
+func (w Wallet) SignTypedData(typedData apitypes.TypedData) (string, error) {
+	hash, err := TypedDataHash(typedData)
+	if err != nil {
+		return "", err
+	}
+
+	signature, err := crypto.Sign(hash, w.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign typed data: %w", err)
+	}
+
+	signature[crypto.RecoveryIDOffset] += 27
+
+	return hexutil.Encode(signature), nil
+}
+
+// SignTypedDataJSON signs EIP-712 typed data given as JSON.
+func (w Wallet) SignTypedDataJSON(typedDataJSON []byte) (string, error) {
+	typedData, err := ParseTypedData(typedDataJSON)
+	if err != nil {
+		return "", err
+	}
+
+	return w.SignTypedData(typedData)
+}
+
+// VerifyTypedData verifies that the wallet signed the typed data.
+func (w Wallet) VerifyTypedData(signatureHex string, typedData apitypes.TypedData) error {
+	address, err := TypedDataAddress(signatureHex, typedData)
+	if err != nil {
+		return err
+	}
+
+	if address != w.Address() {
+		return fmt.Errorf("signed by %s: %w", address, ErrInvalidSignature)
+	}
+
+	return nil
+}
+
+// TypedDataAddress returns the address of the signer of EIP-712 typed data.
+func TypedDataAddress(signatureHex string, typedData apitypes.TypedData) (string, error) {
+	hash, err := TypedDataHash(typedData)
+	if err != nil {
+		return "", err
+	}
+
+	address, err := recoverAddress(hash, signatureHex)
+	if err != nil {
+		return "", err
+	}
+
+	return address.Hex(), nil
+}
@@ -0,0 +1,109 @@
+package eth
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// mailTypedData is the example message of EIP-712
+const mailTypedData = `{
+  "types": {
+    "EIP712Domain": [
+      {"name": "name", "type": "string"},
+      {"name": "version", "type": "string"},
+      {"name": "chainId", "type": "uint256"},
+      {"name": "verifyingContract", "type": "address"}
+    ],
+    "Person": [
+      {"name": "name", "type": "string"},
+      {"name": "wallet", "type": "address"}
+    ],
+    "Mail": [
+      {"name": "from", "type": "Person"},
+      {"name": "to", "type": "Person"},
+      {"name": "contents", "type": "string"}
+    ]
+  },
+  "primaryType": "Mail",
+  "domain": {
+    "name": "Ether Mail",
+    "version": "1",
+    "chainId": 1,
+    "verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+  },
+  "message": {
+    "from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+    "to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+    "contents": "Hello, Bob!"
+  }
+}`
+
+func TestWallet_SignTypedData(t *testing.T) {
+	cow, err := New(WithPrivateKey(hex.EncodeToString(crypto.Keccak256([]byte("cow")))))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	typedData, err := ParseTypedData([]byte(mailTypedData))
+	if err != nil {
+		t.Fatalf("ParseTypedData() error = %v", err)
+	}
+
+	hash, err := TypedDataHash(typedData)
+	if err != nil {
+		t.Fatalf("TypedDataHash() error = %v", err)
+	}
+	if got, want := hexutil.Encode(hash), "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"; got != want {
+		t.Errorf("TypedDataHash() = %s, want %s", got, want)
+	}
+
+	signature, err := cow.SignTypedDataJSON([]byte(mailTypedData))
+	if err != nil {
+		t.Fatalf("SignTypedDataJSON() error = %v", err)
+	}
+	want := "0x4355c47d63924e8a72e509b65029052eb6c299d53a04e167c5775fd466751c9d" +
+		"07299936d304c153f6443dfa05f40ff007d72911b6f72307f996231605b915621c"
+	if signature != want {
+		t.Errorf("SignTypedDataJSON() = %s, want %s", signature, want)
+	}
+
+	other, err := New(WithMnemonic(testMnemonic))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tampered := typedData
+	tampered.Message = map[string]any{
+		"from":     typedData.Message["from"],
+		"to":       typedData.Message["to"],
+		"contents": "Hello, Eve!",
+	}
+
+	tests := []struct {
+		name      string
+		wallet    *Wallet
+		signature string
+		wantErr   error
+	}{
+		{name: "Signer", wallet: cow, signature: signature},
+		{name: "Other wallet", wallet: other, signature: signature, wantErr: ErrInvalidSignature},
+		{name: "Short signature", wallet: cow, signature: signature[:20], wantErr: ErrInvalidSignature},
+		{name: "Not hex", wallet: cow, signature: "signature", wantErr: ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.wallet.VerifyTypedData(tt.signature, typedData); !errors.Is(err, tt.wantErr) {
+				t.Errorf("VerifyTypedData() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := cow.VerifyTypedData(signature, tampered); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifyTypedData(tampered) error = %v, want %v", err, ErrInvalidSignature)
+	}
+}
@@ -0,0 +1,100 @@
+package jwt
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	// ErrKeyNotFound is returned when no key matches the requested key id
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrInvalidKey is returned when a key could not be decoded
+	ErrInvalidKey = errors.New("invalid key")
+)
+
+// keyTypeRSA is the JWK key type for RSA keys
+const keyTypeRSA = "RSA"
+
+// JWK is a JSON Web Key holding an RSA public key
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK creates a new JWK from an RSA public key
+func NewJWK(kid string, key *rsa.PublicKey) JWK {
+	return JWK{
+		Kty: keyTypeRSA,
+		Kid: kid,
+		Use: "sig",
+		Alg: "RS256",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// PublicKey decodes the JWK into an RSA public key
+func (k JWK) PublicKey() (*rsa.PublicKey, error) {
+	if k.Kty != keyTypeRSA {
+		return nil, fmt.Errorf("unsupported key type %q: %w", k.Kty, ErrInvalidKey)
+	}
+
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("decode modulus: %w", ErrInvalidKey)
+	}
+
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("decode exponent: %w", ErrInvalidKey)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 2 || exponent.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("public key out of range: %w", ErrInvalidKey)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(exponent.Int64()),
+	}, nil
+}
+
+// ParseJWKS decodes a JSON Web Key Set
+func ParseJWKS(b []byte) (*JWKS, error) {
+	var set JWKS
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, fmt.Errorf("parse jwks: %w", err)
+	}
+
+	return &set, nil
+}
+
+// Key returns the public key with the given key id. If kid is empty and the
+// set holds exactly one key, that key is returned.
+func (s JWKS) Key(kid string) (*rsa.PublicKey, error) {
+	if kid == "" && len(s.Keys) == 1 {
+		return s.Keys[0].PublicKey()
+	}
+
+	for _, k := range s.Keys {
+		if k.Kid == kid {
+			return k.PublicKey()
+		}
+	}
+
+	return nil, fmt.Errorf("kid %q: %w", kid, ErrKeyNotFound)
+}
@@ -0,0 +1,68 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestJWKS_Key(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	b, err := json.Marshal(JWKS{Keys: []JWK{NewJWK("one", &privateKey.PublicKey)}})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	set, err := ParseJWKS(b)
+	if err != nil {
+		t.Fatalf("ParseJWKS() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		kid     string
+		wantErr error
+	}{
+		{name: "Matching kid", kid: "one"},
+		{name: "Empty kid with single key", kid: ""},
+		{name: "Unknown kid", kid: "two", wantErr: ErrKeyNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := set.Key(tt.kid)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Key(%q) error = %v, wantErr %v", tt.kid, err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !got.Equal(&privateKey.PublicKey) {
+				t.Errorf("Key(%q) = %v, want %v", tt.kid, got, &privateKey.PublicKey)
+			}
+		})
+	}
+}
+
+func TestJWK_PublicKey(t *testing.T) {
+	tests := []struct {
+		name string
+		jwk  JWK
+	}{
+		{name: "Wrong key type", jwk: JWK{Kty: "EC", N: "AQAB", E: "AQAB"}},
+		{name: "Bad modulus", jwk: JWK{Kty: "RSA", N: "!!", E: "AQAB"}},
+		{name: "Empty modulus", jwk: JWK{Kty: "RSA", N: "", E: "AQAB"}},
+		{name: "Exponent too small", jwk: JWK{Kty: "RSA", N: "AQAB", E: "AQ"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.jwk.PublicKey(); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("PublicKey() error = %v, want %v", err, ErrInvalidKey)
+			}
+		})
+	}
+}
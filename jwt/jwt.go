@@ -28,14 +28,32 @@ var (
 type JWT struct {
 	privateKey *rsa.PrivateKey
 	publicKey  *rsa.PublicKey
+	observer   Observer
+	limits     *Limits
+}
+
+// Option is a functional option for creating a JWT.
+type Option func(*JWT)
+
+// WithObserver sets the observer notified on every create, sign and parse.
+func WithObserver(o Observer) Option {
+	return func(j *JWT) {
+		j.observer = o
+	}
 }
 
 // New creates a new instance of JWT util
-func New(privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) *JWT {
-	return &JWT{
+func New(privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey, opts ...Option) *JWT {
+	j := JWT{
 		privateKey: privateKey,
 		publicKey:  publicKey,
 	}
+
+	for _, opt := range opts {
+		opt(&j)
+	}
+
+	return &j
 }
 
 // CreateAndSign a new jwt token
@@ -55,23 +73,36 @@ func (j JWT) Create(ttl time.Duration, claims jwt.MapClaims) (*Token, error) {
 	claims["exp"] = now.Add(ttl).Unix() // The expiration time after which the token must be disregarded.
 	claims["iat"] = now.Unix()          // The time at which the token was issued.
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token := &Token{Token: jwt.NewWithClaims(jwt.SigningMethodRS256, claims)}
+	j.notify(Event{Op: OpCreate, Reason: ReasonOK, Token: token})
 
-	return &Token{Token: token}, nil
+	return token, nil
 }
 
 // Create generates a new jwt token string
 func (j JWT) Sign(token *Token) (string, error) {
 	tokenStr, err := token.SignedString(j.privateKey)
 	if err != nil {
+		j.notify(Event{Op: OpSign, Reason: ReasonInvalid, Token: token, Err: err})
+
 		return "", fmt.Errorf("create: sign token: %w", err)
 	}
 
+	j.notify(Event{Op: OpSign, Reason: ReasonOK, Token: token})
+
 	return tokenStr, nil
 }
 
 // Parse takes in a jwt token string parses it, validates it and return a *Token
 func (j JWT) Parse(token string, validate bool) (*Token, error) {
+	if j.limits != nil {
+		if err := j.limits.check(token); err != nil {
+			j.notify(Event{Op: OpParse, Reason: ReasonLimit, Err: err})
+
+			return nil, fmt.Errorf("parse: %w", err)
+		}
+	}
+
 	tok, err := jwt.Parse(token, func(jwtToken *jwt.Token) (interface{}, error) {
 		if _, ok := jwtToken.Method.(*jwt.SigningMethodRSA); !ok {
 			return nil, fmt.Errorf("unexpected method %s: %w", jwtToken.Header["alg"], ErrTokenParse)
@@ -86,6 +117,8 @@ func (j JWT) Parse(token string, validate bool) (*Token, error) {
 	})
 
 	if tok == nil {
+		j.notify(Event{Op: OpParse, Reason: ReasonMalformed, Err: err})
+
 		return nil, fmt.Errorf("parse: %w", ErrTokenParse)
 	}
 
@@ -94,12 +127,25 @@ func (j JWT) Parse(token string, validate bool) (*Token, error) {
 	}
 
 	if err != nil && !validate && err.Error() != "key is of invalid type" {
+		j.notify(Event{Op: OpParse, Reason: Classify(err), Token: &t, Err: err})
+
 		return nil, fmt.Errorf("parse: %w", err)
 	}
 
 	if !tok.Valid && validate {
-		return nil, fmt.Errorf("parse: %w", ErrTokenValidate)
+		j.notify(Event{Op: OpParse, Reason: Classify(err), Token: &t, Err: err})
+
+		return nil, fmt.Errorf("parse: %w: %w", ErrTokenValidate, err)
 	}
 
+	j.notify(Event{Op: OpParse, Reason: ReasonOK, Token: &t})
+
 	return &t, nil
 }
+
+// notify reports the event to the observer, if one is set
+func (j JWT) notify(e Event) {
+	if j.observer != nil {
+		j.observer.Observe(e)
+	}
+}
@@ -0,0 +1,181 @@
+// Package jwttest provides helpers for testing code that consumes jwt and
+// jwthttp: throwaway keys, tokens in valid and invalid variants, context
+// injection and an httptest backed JWKS server.
+package jwttest
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/euforic/pkg-go/jwt"
+	jwthttp "github.com/euforic/pkg-go/jwt/middleware/http"
+	jwtgo "github.com/golang-jwt/jwt"
+)
+
+const (
+	// keySize is the size of the generated keys
+	keySize = 2048
+	// kidSize is the number of random bytes in a generated key id
+	kidSize = 8
+	// DefaultTTL is the lifetime of tokens minted by Keys.Token
+	DefaultTTL = time.Hour
+)
+
+// Keys holds a throwaway RSA key pair and its key id
+type Keys struct {
+	Kid     string
+	Private *rsa.PrivateKey
+	Public  *rsa.PublicKey
+}
+
+// NewKeys generates a new throwaway key pair with a random key id
+func NewKeys(tb testing.TB) *Keys {
+	tb.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, keySize)
+	if err != nil {
+		tb.Fatalf("jwttest: generate key: %v", err)
+	}
+
+	kid := make([]byte, kidSize)
+	if _, err := rand.Read(kid); err != nil {
+		tb.Fatalf("jwttest: generate kid: %v", err)
+	}
+
+	return &Keys{
+		Kid:     hex.EncodeToString(kid),
+		Private: privateKey,
+		Public:  &privateKey.PublicKey,
+	}
+}
+
+// JWT returns a *jwt.JWT using the key pair
+func (k *Keys) JWT() *jwt.JWT {
+	return jwt.New(k.Private, k.Public)
+}
+
+// JWK returns the public key as a JSON Web Key
+func (k *Keys) JWK() jwt.JWK {
+	return jwt.NewJWK(k.Kid, k.Public)
+}
+
+// Token mints a valid signed token holding the claims that expires after DefaultTTL
+func (k *Keys) Token(tb testing.TB, claims jwtgo.MapClaims) string {
+	tb.Helper()
+
+	return k.sign(tb, k.Private, DefaultTTL, claims)
+}
+
+// TokenWithTTL mints a signed token holding the claims that expires after ttl
+func (k *Keys) TokenWithTTL(tb testing.TB, ttl time.Duration, claims jwtgo.MapClaims) string {
+	tb.Helper()
+
+	return k.sign(tb, k.Private, ttl, claims)
+}
+
+// ExpiredToken mints a correctly signed token that expired an hour ago
+func (k *Keys) ExpiredToken(tb testing.TB, claims jwtgo.MapClaims) string {
+	tb.Helper()
+
+	return k.sign(tb, k.Private, -DefaultTTL, claims)
+}
+
+// NotYetValidToken mints a correctly signed token whose nbf is an hour from now
+func (k *Keys) NotYetValidToken(tb testing.TB, claims jwtgo.MapClaims) string {
+	tb.Helper()
+
+	claims = maps.Clone(claims)
+	if claims == nil {
+		claims = jwtgo.MapClaims{}
+	}
+	claims["nbf"] = time.Now().Add(DefaultTTL).Unix()
+
+	return k.sign(tb, k.Private, 2*DefaultTTL, claims) //nolint:mnd
+}
+
+// WrongSignatureToken mints a token carrying the key id of k but signed with
+// an unrelated key, so that it fails signature verification
+func (k *Keys) WrongSignatureToken(tb testing.TB, claims jwtgo.MapClaims) string {
+	tb.Helper()
+
+	return k.sign(tb, NewKeys(tb).Private, DefaultTTL, claims)
+}
+
+// Parse parses and validates a raw token with the key pair
+func (k *Keys) Parse(tb testing.TB, raw string) *jwt.Token {
+	tb.Helper()
+
+	token, err := k.JWT().Parse(raw, true)
+	if err != nil {
+		tb.Fatalf("jwttest: parse token: %v", err)
+	}
+
+	return token
+}
+
+// sign creates a token with the claims and signs it with the private key
+func (k *Keys) sign(tb testing.TB, privateKey *rsa.PrivateKey, ttl time.Duration, claims jwtgo.MapClaims) string {
+	tb.Helper()
+
+	claims = maps.Clone(claims)
+	if claims == nil {
+		claims = jwtgo.MapClaims{}
+	}
+
+	j := jwt.New(privateKey, &privateKey.PublicKey)
+
+	token, err := j.Create(ttl, claims)
+	if err != nil {
+		tb.Fatalf("jwttest: create token: %v", err)
+	}
+	token.Header["kid"] = k.Kid
+
+	tokenStr, err := j.Sign(token)
+	if err != nil {
+		tb.Fatalf("jwttest: sign token: %v", err)
+	}
+
+	return tokenStr
+}
+
+// NewContext returns a copy of ctx holding the token the way jwthttp.TokenMiddleware sets it
+func NewContext(ctx context.Context, token *jwt.Token) context.Context {
+	return jwthttp.NewContext(ctx, token)
+}
+
+// WithToken returns a shallow copy of r whose context holds the token
+func WithToken(r *http.Request, token *jwt.Token) *http.Request {
+	return r.WithContext(NewContext(r.Context(), token))
+}
+
+// NewJWKSServer starts an httptest server serving the public keys as a JWKS
+// document on every path. The server is closed when the test finishes.
+func NewJWKSServer(tb testing.TB, keys ...*Keys) *httptest.Server {
+	tb.Helper()
+
+	set := jwt.JWKS{Keys: make([]jwt.JWK, 0, len(keys))}
+	for _, k := range keys {
+		set.Keys = append(set.Keys, k.JWK())
+	}
+
+	body, err := json.Marshal(set)
+	if err != nil {
+		tb.Fatalf("jwttest: encode jwks: %v", err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	tb.Cleanup(srv.Close)
+
+	return srv
+}
@@ -0,0 +1,100 @@
+package jwttest
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/euforic/pkg-go/jwt"
+	jwthttp "github.com/euforic/pkg-go/jwt/middleware/http"
+	jwtgo "github.com/golang-jwt/jwt"
+)
+
+func TestKeys_Tokens(t *testing.T) {
+	keys := NewKeys(t)
+	claims := jwtgo.MapClaims{"sub": "1234567890"}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "Valid", token: keys.Token(t, claims)},
+		{name: "Expired", token: keys.ExpiredToken(t, claims), wantErr: true},
+		{name: "Not yet valid", token: keys.NotYetValidToken(t, claims), wantErr: true},
+		{name: "Wrong signature", token: keys.WrongSignatureToken(t, claims), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := keys.JWT().Parse(tt.token, true)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if sub := got.GetString("sub"); sub != "1234567890" {
+				t.Errorf("GetString(sub) = %q, want %q", sub, "1234567890")
+			}
+			if kid := got.Header["kid"]; kid != keys.Kid {
+				t.Errorf("Header[kid] = %v, want %v", kid, keys.Kid)
+			}
+		})
+	}
+
+	if _, ok := claims["exp"]; ok {
+		t.Errorf("claims were mutated: %v", claims)
+	}
+}
+
+func TestWithToken(t *testing.T) {
+	keys := NewKeys(t)
+	token := keys.Parse(t, keys.Token(t, jwtgo.MapClaims{"sub": "abc"}))
+
+	r := WithToken(httptest.NewRequest(http.MethodGet, "/", nil), token)
+
+	got, err := jwthttp.TokenFromContext(r.Context())
+	if err != nil {
+		t.Fatalf("TokenFromContext() error = %v", err)
+	}
+	if got != token {
+		t.Errorf("TokenFromContext() = %v, want %v", got, token)
+	}
+}
+
+func TestNewJWKSServer(t *testing.T) {
+	keys := NewKeys(t)
+	srv := NewJWKSServer(t, keys)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+
+	set, err := jwt.ParseJWKS(b)
+	if err != nil {
+		t.Fatalf("ParseJWKS() error = %v", err)
+	}
+
+	got, err := set.Key(keys.Kid)
+	if err != nil {
+		t.Fatalf("Key() error = %v", err)
+	}
+	if !got.Equal(keys.Public) {
+		t.Errorf("Key() = %v, want %v", got, keys.Public)
+	}
+}
@@ -0,0 +1,210 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrTokenLimit is returned when the token exceeds a configured limit
+	ErrTokenLimit = errors.New("token exceeds limit")
+	// ErrTokenHeader is returned when the token header holds a rejected parameter
+	ErrTokenHeader = errors.New("unsupported token header")
+)
+
+// registeredHeaders are the header parameters registered by RFC 7515
+var registeredHeaders = []string{"alg", "jku", "jwk", "kid", "x5u", "x5c", "x5t", "x5t#S256", "typ", "cty", "crit"} //nolint:gochecknoglobals
+
+// strictHeaders are the header parameters accepted when Limits.StrictHeaders is set
+var strictHeaders = []string{"alg", "typ", "kid", "cty", "crit"} //nolint:gochecknoglobals
+
+// Limits bounds the tokens accepted by Parse. They are checked on the raw
+// token before any signature work is done. A zero value disables a limit.
+type Limits struct {
+	// MaxTokenSize is the maximum length of the raw token in bytes
+	MaxTokenSize int
+	// MaxHeaderSize is the maximum size of the decoded header in bytes
+	MaxHeaderSize int
+	// MaxClaims is the maximum number of top level claims
+	MaxClaims int
+	// MaxDepth is the maximum nesting depth of the claims, the claims object itself being 1
+	MaxDepth int
+	// StrictHeaders rejects header parameters other than alg, typ, kid, cty,
+	// crit and those listed in AllowedHeaders or SupportedCrit. This rejects
+	// jku, x5u, jwk and x5c.
+	StrictHeaders bool
+	// AllowedHeaders are extra header parameters accepted with StrictHeaders
+	AllowedHeaders []string
+	// SupportedCrit are the crit extensions the caller understands. Tokens
+	// listing any other extension in crit are rejected.
+	SupportedCrit []string
+}
+
+// DefaultLimits returns limits suitable for services parsing untrusted tokens
+func DefaultLimits() Limits {
+	return Limits{
+		MaxTokenSize:  8192, //nolint:mnd
+		MaxHeaderSize: 1024, //nolint:mnd
+		MaxClaims:     64,   //nolint:mnd
+		MaxDepth:      8,    //nolint:mnd
+		StrictHeaders: true,
+	}
+}
+
+// WithLimits sets the limits checked by Parse before verifying a token.
+func WithLimits(l Limits) Option {
+	return func(j *JWT) {
+		j.limits = &l
+	}
+}
+
+// check verifies the raw token against the limits. Tokens that are not made
+// of three segments are left to the parser to reject.
+func (l Limits) check(token string) error {
+	if l.MaxTokenSize > 0 && len(token) > l.MaxTokenSize {
+		return fmt.Errorf("token size %d exceeds %d: %w", len(token), l.MaxTokenSize, ErrTokenLimit)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 { //nolint:mnd
+		return nil
+	}
+
+	if l.MaxHeaderSize > 0 && base64.RawURLEncoding.DecodedLen(len(parts[0])) > l.MaxHeaderSize {
+		return fmt.Errorf("header size exceeds %d: %w", l.MaxHeaderSize, ErrTokenLimit)
+	}
+
+	header, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil //nolint:nilerr // malformed tokens are rejected by the parser
+	}
+
+	if err := l.checkHeader(header); err != nil {
+		return err
+	}
+
+	claims, err := decodeSegment(parts[1])
+	if err != nil {
+		return nil //nolint:nilerr // malformed tokens are rejected by the parser
+	}
+
+	return l.checkClaims(claims)
+}
+
+// checkHeader enforces crit and the strict header policy
+func (l Limits) checkHeader(b []byte) error {
+	header := map[string]any{}
+	if err := json.Unmarshal(b, &header); err != nil {
+		return nil //nolint:nilerr // malformed tokens are rejected by the parser
+	}
+
+	if crit, ok := header["crit"]; ok {
+		if err := l.checkCrit(header, crit); err != nil {
+			return err
+		}
+	}
+
+	if !l.StrictHeaders {
+		return nil
+	}
+
+	for name := range header {
+		if !slices.Contains(strictHeaders, name) && !slices.Contains(l.AllowedHeaders, name) && !slices.Contains(l.SupportedCrit, name) {
+			return fmt.Errorf("header parameter %q: %w", name, ErrTokenHeader)
+		}
+	}
+
+	return nil
+}
+
+// checkCrit applies the crit rules of RFC 7515 section 4.1.11
+func (l Limits) checkCrit(header map[string]any, crit any) error {
+	list, ok := crit.([]any)
+	if !ok || len(list) == 0 {
+		return fmt.Errorf("crit must be a non-empty array: %w", ErrTokenHeader)
+	}
+
+	for _, v := range list {
+		name, ok := v.(string)
+		if !ok || name == "" {
+			return fmt.Errorf("crit must hold parameter names: %w", ErrTokenHeader)
+		}
+
+		if slices.Contains(registeredHeaders, name) {
+			return fmt.Errorf("crit lists registered parameter %q: %w", name, ErrTokenHeader)
+		}
+
+		if !slices.Contains(l.SupportedCrit, name) {
+			return fmt.Errorf("crit lists unsupported extension %q: %w", name, ErrTokenHeader)
+		}
+
+		if _, ok := header[name]; !ok {
+			return fmt.Errorf("crit lists missing parameter %q: %w", name, ErrTokenHeader)
+		}
+	}
+
+	return nil
+}
+
+// checkClaims counts the top level claims and the nesting depth without
+// decoding the claims into values
+func (l Limits) checkClaims(b []byte) error {
+	if l.MaxClaims <= 0 && l.MaxDepth <= 0 {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	depth, claims := 0, 0
+	expectKey := false
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return nil //nolint:nilerr // malformed tokens are rejected by the parser
+		}
+
+		if depth == 1 && expectKey {
+			claims++
+			if l.MaxClaims > 0 && claims > l.MaxClaims {
+				return fmt.Errorf("claims exceed %d: %w", l.MaxClaims, ErrTokenLimit)
+			}
+			expectKey = false
+
+			continue
+		}
+
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+			if l.MaxDepth > 0 && depth > l.MaxDepth {
+				return fmt.Errorf("claims nesting exceeds %d: %w", l.MaxDepth, ErrTokenLimit)
+			}
+			expectKey = depth == 1
+
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+
+		expectKey = depth == 1
+	}
+}
+
+// decodeSegment decodes a base64url token segment with or without padding
+func decodeSegment(seg string) ([]byte, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(seg, "="))
+	if err != nil {
+		return nil, fmt.Errorf("decode segment: %w", err)
+	}
+
+	return b, nil
+}
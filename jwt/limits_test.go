@@ -0,0 +1,73 @@
+package jwt
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func TestJWT_ParseLimits(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	limits := DefaultLimits()
+	limits.MaxClaims = 4
+	limits.MaxDepth = 3
+	limits.SupportedCrit = []string{"exp2"}
+	j := New(privateKey, &privateKey.PublicKey, WithLimits(limits))
+
+	sign := func(header map[string]any, claims jwt.MapClaims) string {
+		t.Helper()
+
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		for k, v := range header {
+			token.Header[k] = v
+		}
+
+		s, err := token.SignedString(privateKey)
+		if err != nil {
+			t.Fatalf("SignedString() error = %v", err)
+		}
+
+		return s
+	}
+
+	exp := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "Within limits", token: sign(nil, jwt.MapClaims{"exp": exp, "a": map[string]any{"b": []any{1}}})},
+		{name: "Token too large", token: sign(nil, jwt.MapClaims{"exp": exp, "a": strings.Repeat("x", 9000)}), wantErr: ErrTokenLimit},
+		{name: "Header too large", token: sign(map[string]any{"kid": strings.Repeat("x", 2000)}, jwt.MapClaims{"exp": exp}), wantErr: ErrTokenLimit},
+		{name: "Too many claims", token: sign(nil, jwt.MapClaims{"exp": exp, "a": 1, "b": 2, "c": 3, "d": 4}), wantErr: ErrTokenLimit},
+		{name: "Nested too deep", token: sign(nil, jwt.MapClaims{"exp": exp, "a": map[string]any{"b": map[string]any{"c": []any{1}}}}), wantErr: ErrTokenLimit},
+		{name: "Unknown header with strict headers", token: sign(map[string]any{"jku": "https://example.com/jwks"}, jwt.MapClaims{"exp": exp}), wantErr: ErrTokenHeader},
+		{name: "Supported crit", token: sign(map[string]any{"crit": []string{"exp2"}, "exp2": 1}, jwt.MapClaims{"exp": exp})},
+		{name: "Unsupported crit", token: sign(map[string]any{"crit": []string{"b64"}, "b64": false}, jwt.MapClaims{"exp": exp}), wantErr: ErrTokenHeader},
+		{name: "Crit with missing parameter", token: sign(map[string]any{"crit": []string{"exp2"}}, jwt.MapClaims{"exp": exp}), wantErr: ErrTokenHeader},
+		{name: "Crit with registered parameter", token: sign(map[string]any{"crit": []string{"alg"}}, jwt.MapClaims{"exp": exp}), wantErr: ErrTokenHeader},
+		{name: "Empty crit", token: sign(map[string]any{"crit": []string{}}, jwt.MapClaims{"exp": exp}), wantErr: ErrTokenHeader},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := j.Parse(tt.token, true)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Parse() error = %v, want nil", err)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
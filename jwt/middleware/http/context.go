@@ -26,3 +26,20 @@ func TokenFromContext(ctx context.Context) (*jwt.Token, error) {
 
 	return token, nil
 }
+
+// NewContext returns a copy of ctx holding the token under ContextKey, the way
+// TokenMiddleware and SessionManager.Middleware set it
+func NewContext(ctx context.Context, token *jwt.Token) context.Context {
+	return context.WithValue(ctx, ContextKey, token)
+}
+
+// SessionID returns the session id claim of the token in the context, or an
+// empty string when the context holds no session
+func SessionID(ctx context.Context) string {
+	token, err := TokenFromContext(ctx)
+	if err != nil {
+		return ""
+	}
+
+	return token.GetString(SessionIDClaim)
+}
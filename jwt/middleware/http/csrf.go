@@ -0,0 +1,275 @@
+package jwthttp
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidCSRFToken is returned when the submitted CSRF token does not match the cookie
+var ErrInvalidCSRFToken = errors.New("invalid csrf token")
+
+const (
+	// DefaultCSRFCookie is the default name of the CSRF cookie
+	DefaultCSRFCookie = "csrf_token"
+	// DefaultCSRFHeader is the default request header carrying the CSRF token
+	DefaultCSRFHeader = "X-Csrf-Token"
+	// DefaultCSRFField is the default form field carrying the CSRF token
+	DefaultCSRFField = "csrf_token"
+	// csrfTokenSize is the number of random bytes in a CSRF token
+	csrfTokenSize = 32
+	// csrfKeySize is the size of the generated CSRF signing key
+	csrfKeySize = 32
+)
+
+// csrfContextKey is the key to use when setting the CSRF token in the context
+const csrfContextKey jwtContextKey = "csrf_context"
+
+// csrf holds the configuration of the CSRF middleware
+type csrf struct {
+	cookie    string
+	header    string
+	field     string
+	path      string
+	domain    string
+	sameSite  http.SameSite
+	insecure  bool
+	key       []byte
+	sessionID func(*http.Request) string
+	// customSessionID is set when the session id is not read from the SessionIDClaim
+	customSessionID bool
+}
+
+// CSRFOpt is a functional option for the CSRF middleware.
+type CSRFOpt func(*csrf)
+
+// WithCSRFCookieName sets the name of the CSRF cookie.
+func WithCSRFCookieName(name string) CSRFOpt {
+	return func(c *csrf) {
+		c.cookie = name
+	}
+}
+
+// WithCSRFHeader sets the request header checked for the CSRF token.
+func WithCSRFHeader(header string) CSRFOpt {
+	return func(c *csrf) {
+		c.header = header
+	}
+}
+
+// WithCSRFField sets the form field checked for the CSRF token.
+func WithCSRFField(field string) CSRFOpt {
+	return func(c *csrf) {
+		c.field = field
+	}
+}
+
+// WithCSRFCookieDomain sets the domain attribute of the CSRF cookie.
+func WithCSRFCookieDomain(domain string) CSRFOpt {
+	return func(c *csrf) {
+		c.domain = domain
+	}
+}
+
+// WithCSRFKey sets the key used to sign CSRF tokens. Instances of a service
+// behind a load balancer must share the key, by default a random key is
+// generated for every middleware.
+func WithCSRFKey(key []byte) CSRFOpt {
+	return func(c *csrf) {
+		c.key = key
+	}
+}
+
+// WithCSRFSessionID sets how the session id that CSRF tokens are bound to is
+// read from the request, by default the SessionIDClaim of the token set in
+// the context by SessionManager.Middleware.
+func WithCSRFSessionID(sessionID func(*http.Request) string) CSRFOpt {
+	return func(c *csrf) {
+		c.sessionID = sessionID
+		c.customSessionID = true
+	}
+}
+
+// WithCSRFInsecureCookie drops the Secure attribute so the cookie works over
+// plain http. It is meant for local development only.
+func WithCSRFInsecureCookie() CSRFOpt {
+	return func(c *csrf) {
+		c.insecure = true
+	}
+}
+
+// CSRFMiddleware implements signed double-submit cookie CSRF protection. It
+// makes sure every client holds a token in a cookie readable by scripts and
+// rejects unsafe requests (anything but GET, HEAD, OPTIONS and TRACE) with
+// 403 Forbidden unless the same token is sent back in the header or form field.
+// Tokens are bound to the session with an HMAC of the session id, so a token
+// is replaced when the session changes. Wrap it in SessionManager.Middleware
+// so the session is in the request context. When a handler starts a new
+// session with SessionManager.Issue, on the ResponseWriter passed on by the
+// middleware, the response also sets a token bound to the new session so
+// that the next unsafe request, such as the first one after sign in, passes.
+func CSRFMiddleware(next http.Handler, opts ...CSRFOpt) http.Handler {
+	c := csrf{
+		cookie:    DefaultCSRFCookie,
+		header:    DefaultCSRFHeader,
+		field:     DefaultCSRFField,
+		path:      "/",
+		sameSite:  http.SameSiteLaxMode,
+		sessionID: func(r *http.Request) string { return SessionID(r.Context()) },
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	var keyErr error
+	if c.key == nil {
+		c.key = make([]byte, csrfKeySize)
+		_, keyErr = rand.Read(c.key)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if keyErr != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+			return
+		}
+
+		sessionID := c.sessionID(r)
+
+		var token string
+		if cookie, err := r.Cookie(c.cookie); err == nil && c.bound(cookie.Value, sessionID) {
+			token = cookie.Value
+		}
+
+		if !isSafeMethod(r.Method) && !c.valid(r, token) {
+			http.Error(w, ErrInvalidCSRFToken.Error(), http.StatusForbidden)
+
+			return
+		}
+
+		if token == "" {
+			var err error
+			if token, err = c.setToken(w, sessionID); err != nil {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+				return
+			}
+		}
+
+		cw := &csrfWriter{ResponseWriter: w, c: &c}
+		next.ServeHTTP(cw, r.WithContext(context.WithValue(r.Context(), csrfContextKey, token)))
+	})
+}
+
+// csrfWriter is the ResponseWriter passed on by CSRFMiddleware, through which
+// SessionManager.Issue sets a token bound to a new session
+type csrfWriter struct {
+	http.ResponseWriter
+	c *csrf
+}
+
+// Unwrap returns the wrapped ResponseWriter for http.ResponseController
+func (w *csrfWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// rotateCSRFToken sets a CSRF token bound to the session id when w was passed
+// on by CSRFMiddleware, directly or wrapped by other middleware
+func rotateCSRFToken(w http.ResponseWriter, sessionID string) error {
+	for {
+		switch rw := w.(type) {
+		case *csrfWriter:
+			if rw.c.customSessionID {
+				return nil
+			}
+
+			_, err := rw.c.setToken(rw.ResponseWriter, sessionID)
+
+			return err
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = rw.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
+
+// setToken sets the cookie to a new token bound to the session id and returns it
+func (c csrf) setToken(w http.ResponseWriter, sessionID string) (string, error) {
+	b := make([]byte, csrfTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("csrf: token: %w", err)
+	}
+
+	nonce := base64.RawURLEncoding.EncodeToString(b)
+	token := nonce + "." + c.sign(nonce, sessionID)
+	http.SetCookie(w, &http.Cookie{
+		Name:     c.cookie,
+		Value:    token,
+		Path:     c.path,
+		Domain:   c.domain,
+		Secure:   !c.insecure,
+		SameSite: c.sameSite,
+	})
+
+	return token, nil
+}
+
+// CSRFToken returns the CSRF token set by CSRFMiddleware so that handlers can
+// embed it in forms
+func CSRFToken(ctx context.Context) string {
+	token, _ := ctx.Value(csrfContextKey).(string)
+
+	return token
+}
+
+// valid reports whether the request carries the expected CSRF token
+func (c csrf) valid(r *http.Request, token string) bool {
+	if token == "" {
+		return false
+	}
+
+	submitted := r.Header.Get(c.header)
+	if submitted == "" {
+		submitted = r.PostFormValue(c.field)
+	}
+
+	return hmac.Equal([]byte(submitted), []byte(token))
+}
+
+// bound reports whether the token was signed for the session id
+func (c csrf) bound(token, sessionID string) bool {
+	nonce, mac, ok := strings.Cut(token, ".")
+	if !ok || nonce == "" {
+		return false
+	}
+
+	return hmac.Equal([]byte(mac), []byte(c.sign(nonce, sessionID)))
+}
+
+// sign returns the encoded HMAC of the session id and token nonce
+func (c csrf) sign(nonce, sessionID string) string {
+	h := hmac.New(sha256.New, c.key)
+	h.Write([]byte(sessionID))
+	h.Write([]byte{0})
+	h.Write([]byte(nonce))
+
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// isSafeMethod reports whether the method is defined as safe by RFC 9110
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
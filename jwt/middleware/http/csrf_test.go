@@ -0,0 +1,179 @@
+package jwthttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	jwtgo "github.com/golang-jwt/jwt"
+)
+
+func TestCSRFMiddleware(t *testing.T) {
+	var gotToken string
+	h := CSRFMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		gotToken = CSRFToken(r.Context())
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != DefaultCSRFCookie {
+		t.Fatalf("GET set cookies %v, want %s", cookies, DefaultCSRFCookie)
+	}
+	token := cookies[0].Value
+	if gotToken != token {
+		t.Fatalf("CSRFToken() = %q, want %q", gotToken, token)
+	}
+
+	tests := []struct {
+		name       string
+		cookie     string
+		header     string
+		form       string
+		wantStatus int
+	}{
+		{name: "Matching header", cookie: token, header: token, wantStatus: http.StatusOK},
+		{name: "Matching form field", cookie: token, form: token, wantStatus: http.StatusOK},
+		{name: "Mismatched header", cookie: token, header: "other", wantStatus: http.StatusForbidden},
+		{name: "Missing submitted token", cookie: token, wantStatus: http.StatusForbidden},
+		{name: "Missing cookie", header: token, wantStatus: http.StatusForbidden},
+		{name: "Unsigned token", cookie: "forged", header: "forged", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{}
+			if tt.form != "" {
+				form.Set(DefaultCSRFField, tt.form)
+			}
+
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.header != "" {
+				r.Header.Set(DefaultCSRFHeader, tt.header)
+			}
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: DefaultCSRFCookie, Value: tt.cookie})
+			}
+
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("POST status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCSRFMiddleware_SessionBinding(t *testing.T) {
+	h := CSRFMiddleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+		WithCSRFKey([]byte("csrf-key")),
+		WithCSRFSessionID(func(r *http.Request) string { return r.Header.Get("Session") }),
+	)
+
+	request := func(method, session, token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/", nil)
+		r.Header.Set("Session", session)
+		if token != "" {
+			r.AddCookie(&http.Cookie{Name: DefaultCSRFCookie, Value: token})
+			r.Header.Set(DefaultCSRFHeader, token)
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+
+		return rec
+	}
+
+	cookies := request(http.MethodGet, "session-a", "").Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("GET set %d cookies, want 1", len(cookies))
+	}
+	token := cookies[0].Value
+
+	if rec := request(http.MethodPost, "session-a", token); rec.Code != http.StatusOK {
+		t.Errorf("POST(same session) status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if rec := request(http.MethodPost, "session-b", token); rec.Code != http.StatusForbidden {
+		t.Errorf("POST(other session) status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+	if cookies := request(http.MethodGet, "session-b", token).Result().Cookies(); len(cookies) != 1 || cookies[0].Value == token {
+		t.Errorf("GET(other session) cookies = %v, want a new token", cookies)
+	}
+}
+
+func TestCSRFMiddleware_SignIn(t *testing.T) {
+	m, err := NewSessionManager(newTestJWT(t))
+	if err != nil {
+		t.Fatalf("NewSessionManager() error = %v", err)
+	}
+
+	h := m.Middleware(CSRFMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			if err := m.Issue(w, jwtgo.MapClaims{"sub": "abc"}); err != nil {
+				t.Errorf("Issue() error = %v", err)
+			}
+		case "/logout":
+			m.Clear(w)
+		}
+	})))
+
+	// jar holds the cookies of the client, as a browser would
+	jar := map[string]string{}
+	request := func(method, path, token string) int {
+		r := httptest.NewRequest(method, path, nil)
+		for name, value := range jar {
+			r.AddCookie(&http.Cookie{Name: name, Value: value})
+		}
+		r.Header.Set(DefaultCSRFHeader, token)
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+
+		for _, c := range rec.Result().Cookies() {
+			if c.MaxAge < 0 {
+				delete(jar, c.Name)
+			} else {
+				jar[c.Name] = c.Value
+			}
+		}
+
+		return rec.Code
+	}
+
+	request(http.MethodGet, "/", "")
+	anonymous := jar[DefaultCSRFCookie]
+
+	if code := request(http.MethodPost, "/login", anonymous); code != http.StatusOK {
+		t.Fatalf("POST /login status = %d, want %d", code, http.StatusOK)
+	}
+	if jar[DefaultSessionCookie] == "" || jar[DefaultCSRFCookie] == anonymous {
+		t.Fatalf("POST /login cookies = %v, want a session and a new csrf token", jar)
+	}
+
+	signedIn := jar[DefaultCSRFCookie]
+	if code := request(http.MethodPost, "/", anonymous); code != http.StatusForbidden {
+		t.Errorf("POST with the token from before sign in status = %d, want %d", code, http.StatusForbidden)
+	}
+	if code := request(http.MethodPost, "/", signedIn); code != http.StatusOK {
+		t.Errorf("POST after sign in status = %d, want %d", code, http.StatusOK)
+	}
+	if jar[DefaultCSRFCookie] != signedIn {
+		t.Errorf("POST after sign in replaced the csrf token")
+	}
+
+	if code := request(http.MethodPost, "/logout", signedIn); code != http.StatusOK {
+		t.Fatalf("POST /logout status = %d, want %d", code, http.StatusOK)
+	}
+	if _, ok := jar[DefaultSessionCookie]; ok || jar[DefaultCSRFCookie] == signedIn {
+		t.Fatalf("POST /logout cookies = %v, want no session and a new csrf token", jar)
+	}
+	if code := request(http.MethodPost, "/", jar[DefaultCSRFCookie]); code != http.StatusOK {
+		t.Errorf("POST after sign out status = %d, want %d", code, http.StatusOK)
+	}
+}
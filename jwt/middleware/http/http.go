@@ -1,21 +1,49 @@
 package jwthttp
 
 import (
-	"context"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/euforic/pkg-go/jwt"
 )
 
-// TokenMiddleware ...
-func TokenMiddleware(j *jwt.JWT, next http.Handler) http.Handler {
+// ErrSessionToken is returned when a session token is sent as a bearer token
+var ErrSessionToken = errors.New("session token used as bearer token")
+
+// middleware holds the configuration of TokenMiddleware
+type middleware struct {
+	observer jwt.Observer
+}
+
+// MiddlewareOpt is a functional option for TokenMiddleware.
+type MiddlewareOpt func(*middleware)
+
+// WithObserver sets the observer notified with a jwt.OpAuthenticate event for
+// every request, including requests without a token.
+func WithObserver(o jwt.Observer) MiddlewareOpt {
+	return func(m *middleware) {
+		m.observer = o
+	}
+}
+
+// TokenMiddleware verifies the bearer token of the Authorization header and
+// adds it to the request context under ContextKey. Requests without a valid
+// token, including session tokens issued by a SessionManager, are passed
+// through unchanged.
+func TokenMiddleware(j *jwt.JWT, next http.Handler, opts ...MiddlewareOpt) http.Handler {
+	var m middleware
+	for _, opt := range opts {
+		opt(&m)
+	}
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := strings.Split(r.Header.Get("Authorization"), " ")
 
 		// Check if the token is present
 		tokenPartsLen := 2
 		if len(authHeader) != tokenPartsLen {
+			m.notify(jwt.Event{Op: jwt.OpAuthenticate, Reason: jwt.ReasonMissing})
 			next.ServeHTTP(w, r)
 
 			return
@@ -24,15 +52,32 @@ func TokenMiddleware(j *jwt.JWT, next http.Handler) http.Handler {
 		// Parse the token
 		token, err := j.Parse(authHeader[1], true)
 		if err != nil {
+			m.notify(jwt.Event{Op: jwt.OpAuthenticate, Reason: jwt.Classify(err), Err: err})
 			next.ServeHTTP(w, r)
 
 			return
 		}
 
+		if isSessionToken(token) {
+			m.notify(jwt.Event{Op: jwt.OpAuthenticate, Reason: jwt.ReasonInvalid, Token: token, Err: ErrSessionToken})
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		m.notify(jwt.Event{Op: jwt.OpAuthenticate, Reason: jwt.ReasonOK, Token: token})
+
 		// Add the token to the context
-		r = r.WithContext(context.WithValue(r.Context(), ContextKey, token))
+		r = r.WithContext(NewContext(r.Context(), token))
 
 		// Call the next handler, which can be another middleware in the chain, or the final handler.
 		next.ServeHTTP(w, r)
 	})
 }
+
+// notify reports the event to the observer, if one is set
+func (m middleware) notify(e jwt.Event) {
+	if m.observer != nil {
+		m.observer.Observe(e)
+	}
+}
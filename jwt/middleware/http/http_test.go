@@ -0,0 +1,72 @@
+package jwthttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/euforic/pkg-go/jwt"
+	jwtgo "github.com/golang-jwt/jwt"
+)
+
+func TestTokenMiddleware(t *testing.T) {
+	j := newTestJWT(t)
+	counters := jwt.NewCounters()
+
+	valid, err := j.CreatAndSign(time.Hour, jwtgo.MapClaims{"sub": "abc"})
+	if err != nil {
+		t.Fatalf("CreatAndSign() error = %v", err)
+	}
+	expired, err := j.CreatAndSign(-time.Hour, jwtgo.MapClaims{"sub": "abc"})
+	if err != nil {
+		t.Fatalf("CreatAndSign() error = %v", err)
+	}
+
+	m, err := NewSessionManager(j)
+	if err != nil {
+		t.Fatalf("NewSessionManager() error = %v", err)
+	}
+	rec := httptest.NewRecorder()
+	if err := m.Issue(rec, jwtgo.MapClaims{"sub": "abc"}); err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	session := rec.Result().Cookies()[0].Value
+
+	tests := []struct {
+		name       string
+		header     string
+		wantToken  bool
+		wantReason jwt.Reason
+	}{
+		{name: "Valid token", header: "Bearer " + valid, wantToken: true, wantReason: jwt.ReasonOK},
+		{name: "Expired token", header: "Bearer " + expired, wantToken: false, wantReason: jwt.ReasonExpired},
+		{name: "Missing token", header: "", wantToken: false, wantReason: jwt.ReasonMissing},
+		{name: "Session token", header: "Bearer " + session, wantToken: false, wantReason: jwt.ReasonInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotToken bool
+			h := TokenMiddleware(j, http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				_, err := TokenFromContext(r.Context())
+				gotToken = err == nil
+			}), WithObserver(counters))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set("Authorization", tt.header)
+			}
+
+			before := counters.Get(jwt.OpAuthenticate, tt.wantReason)
+			h.ServeHTTP(httptest.NewRecorder(), r)
+
+			if gotToken != tt.wantToken {
+				t.Errorf("token in context = %v, want %v", gotToken, tt.wantToken)
+			}
+			if got := counters.Get(jwt.OpAuthenticate, tt.wantReason) - before; got != 1 {
+				t.Errorf("Get(%s, %s) increased by %d, want 1", jwt.OpAuthenticate, tt.wantReason, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,407 @@
+package jwthttp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/euforic/pkg-go/jwt"
+	jwtgo "github.com/golang-jwt/jwt"
+)
+
+var (
+	// ErrMissingSession is returned when the request has no session cookie
+	ErrMissingSession = errors.New("missing session")
+	// ErrInvalidSession is returned when the session cookie could not be decoded or verified
+	ErrInvalidSession = errors.New("invalid session")
+	// ErrSessionExpired is returned when a session has outlived its maximum age and can not be refreshed
+	ErrSessionExpired = errors.New("session expired")
+)
+
+const (
+	// DefaultSessionCookie is the default name of the session cookie
+	DefaultSessionCookie = "session"
+	// DefaultSessionTTL is the default lifetime of a session
+	DefaultSessionTTL = 24 * time.Hour
+	// DefaultSessionMaxAge is the default time after sign in past which a session is no longer refreshed
+	DefaultSessionMaxAge = 30 * 24 * time.Hour
+	// SessionIDClaim is the claim holding the id of the session
+	SessionIDClaim = "sid"
+	// AuthTimeClaim is the claim holding the time the session was first issued
+	AuthTimeClaim = "auth_time"
+	// sessionIDSize is the number of random bytes in a session id
+	sessionIDSize = 16
+	// sessionTokenType is the typ header of session tokens, which tells them
+	// apart from bearer tokens signed with the same key
+	sessionTokenType = "session+jwt"
+)
+
+// SessionManager issues and reads jwt backed session cookies
+type SessionManager struct {
+	jwt      *jwt.JWT
+	name     string
+	path     string
+	domain   string
+	ttl      time.Duration
+	maxAge   time.Duration
+	sameSite http.SameSite
+	insecure bool
+	aead     cipher.AEAD
+}
+
+// SessionOpt is a functional option for creating a session manager.
+type SessionOpt func(*SessionManager) error
+
+// WithCookieName sets the name of the session cookie.
+func WithCookieName(name string) SessionOpt {
+	return func(m *SessionManager) error {
+		m.name = name
+
+		return nil
+	}
+}
+
+// WithCookiePath sets the path attribute of the session cookie.
+func WithCookiePath(path string) SessionOpt {
+	return func(m *SessionManager) error {
+		m.path = path
+
+		return nil
+	}
+}
+
+// WithCookieDomain sets the domain attribute of the session cookie.
+func WithCookieDomain(domain string) SessionOpt {
+	return func(m *SessionManager) error {
+		m.domain = domain
+
+		return nil
+	}
+}
+
+// WithSessionTTL sets the idle lifetime of a session.
+func WithSessionTTL(ttl time.Duration) SessionOpt {
+	return func(m *SessionManager) error {
+		m.ttl = ttl
+
+		return nil
+	}
+}
+
+// WithSessionMaxAge sets how long after sign in a session may be refreshed.
+// Once it has passed the session expires however active it is.
+func WithSessionMaxAge(maxAge time.Duration) SessionOpt {
+	return func(m *SessionManager) error {
+		m.maxAge = maxAge
+
+		return nil
+	}
+}
+
+// WithSameSite sets the SameSite attribute of the session cookie.
+func WithSameSite(sameSite http.SameSite) SessionOpt {
+	return func(m *SessionManager) error {
+		m.sameSite = sameSite
+
+		return nil
+	}
+}
+
+// WithInsecureCookie drops the Secure attribute so cookies work over plain
+// http. It is meant for local development only.
+func WithInsecureCookie() SessionOpt {
+	return func(m *SessionManager) error {
+		m.insecure = true
+
+		return nil
+	}
+}
+
+// WithEncryption encrypts the signed session with AES-GCM so its claims are
+// not readable by the client. The key must be 16, 24 or 32 bytes long.
+func WithEncryption(key []byte) SessionOpt {
+	return func(m *SessionManager) error {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return fmt.Errorf("session: encryption key: %w", err)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return fmt.Errorf("session: encryption key: %w", err)
+		}
+
+		m.aead = aead
+
+		return nil
+	}
+}
+
+// NewSessionManager creates a new session manager signing sessions with j
+func NewSessionManager(j *jwt.JWT, opts ...SessionOpt) (*SessionManager, error) {
+	m := SessionManager{
+		jwt:      j,
+		name:     DefaultSessionCookie,
+		path:     "/",
+		ttl:      DefaultSessionTTL,
+		maxAge:   DefaultSessionMaxAge,
+		sameSite: http.SameSiteLaxMode,
+	}
+
+	for _, opt := range opts {
+		if err := opt(&m); err != nil {
+			return nil, err
+		}
+	}
+
+	return &m, nil
+}
+
+// Issue signs the claims into a new session and sets the session cookie. A
+// new session gets a random id and the current time as its auth time, both of
+// which are kept when the session is refreshed. The session never expires
+// later than its maximum age after the auth time. When w was passed on by
+// CSRFMiddleware a new session also gets a new CSRF token bound to it.
+func (m *SessionManager) Issue(w http.ResponseWriter, claims jwtgo.MapClaims) error {
+	claims = maps.Clone(claims)
+	if claims == nil {
+		claims = jwtgo.MapClaims{}
+	}
+
+	now := time.Now()
+
+	sessionID, ok := claims[SessionIDClaim].(string)
+	newSession := !ok
+	if newSession {
+		b := make([]byte, sessionIDSize)
+		if _, err := io.ReadFull(rand.Reader, b); err != nil {
+			return fmt.Errorf("session: id: %w", err)
+		}
+		sessionID = base64.RawURLEncoding.EncodeToString(b)
+		claims[SessionIDClaim] = sessionID
+	}
+
+	authTime, ok := claimTime(claims, AuthTimeClaim)
+	if !ok {
+		authTime = now
+		claims[AuthTimeClaim] = now.Unix()
+	}
+
+	ttl := min(m.ttl, authTime.Add(m.maxAge).Sub(now))
+	if ttl <= 0 {
+		return ErrSessionExpired
+	}
+
+	token, err := m.jwt.Create(ttl, claims)
+	if err != nil {
+		return fmt.Errorf("session: %w", err)
+	}
+	token.Header["typ"] = sessionTokenType
+
+	tokenStr, err := m.jwt.Sign(token)
+	if err != nil {
+		return fmt.Errorf("session: %w", err)
+	}
+
+	value, err := m.seal(tokenStr)
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, m.cookie(value, int(ttl.Seconds())))
+
+	if newSession {
+		// Bind the CSRF token of the response to the new session
+		if err := rotateCSRFToken(w, sessionID); err != nil {
+			return fmt.Errorf("session: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Read verifies the session cookie of the request and returns its token. Only
+// tokens issued by Issue are accepted, bearer tokens signed with the same key
+// are rejected.
+func (m *SessionManager) Read(r *http.Request) (*jwt.Token, error) {
+	c, err := r.Cookie(m.name)
+	if err != nil {
+		return nil, ErrMissingSession
+	}
+
+	tokenStr, err := m.open(c.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := m.jwt.Parse(tokenStr, true)
+	if err != nil {
+		return nil, fmt.Errorf("session: %w: %w", err, ErrInvalidSession)
+	}
+
+	if !isSessionToken(token) {
+		return nil, fmt.Errorf("session: not a session token: %w", ErrInvalidSession)
+	}
+
+	if token.GetString(SessionIDClaim) == "" {
+		return nil, fmt.Errorf("session: %s: %w", SessionIDClaim, ErrInvalidSession)
+	}
+
+	return token, nil
+}
+
+// Refresh re-issues the session when less than half of its lifetime remains,
+// sliding the expiry forward while the session is in use. It returns
+// ErrSessionExpired once the session has outlived its maximum age and
+// ErrInvalidSession for tokens not issued as a session. Sessions without an
+// auth time are aged from the time they were issued.
+func (m *SessionManager) Refresh(w http.ResponseWriter, token *jwt.Token) error {
+	if !isSessionToken(token) {
+		return fmt.Errorf("session: not a session token: %w", ErrInvalidSession)
+	}
+
+	claims, ok := token.Claims()
+	if !ok {
+		return fmt.Errorf("session: claims: %w", ErrInvalidSession)
+	}
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("session: exp: %w", ErrInvalidSession)
+	}
+
+	authTime, ok := claimTime(claims, AuthTimeClaim)
+	if !ok {
+		if authTime, ok = claimTime(claims, "iat"); !ok {
+			return fmt.Errorf("session: iat: %w", ErrInvalidSession)
+		}
+		claims[AuthTimeClaim] = authTime.Unix()
+	}
+
+	if time.Since(authTime) >= m.maxAge {
+		return ErrSessionExpired
+	}
+
+	expires := time.Unix(int64(exp), 0)
+	if time.Until(expires) > m.ttl/2 || !expires.Before(authTime.Add(m.maxAge)) { //nolint:mnd
+		return nil
+	}
+
+	return m.Issue(w, claims)
+}
+
+// Clear expires the session cookie. When w was passed on by CSRFMiddleware
+// the CSRF token is replaced with one that is not bound to the session.
+func (m *SessionManager) Clear(w http.ResponseWriter) {
+	http.SetCookie(w, m.cookie("", -1))
+
+	// Without a new token the client only gets one on its next safe request
+	_ = rotateCSRFToken(w, "") //nolint:errcheck
+}
+
+// Middleware verifies the session cookie, slides its expiry and adds the
+// token to the request context under ContextKey. Requests without a valid
+// session are passed through unchanged.
+func (m *SessionManager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := m.Read(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		if err := m.Refresh(w, token); err != nil {
+			if errors.Is(err, ErrSessionExpired) {
+				m.Clear(w)
+			}
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), token)))
+	})
+}
+
+// isSessionToken reports whether the token was issued as a session
+func isSessionToken(token *jwt.Token) bool {
+	typ, _ := token.Header["typ"].(string)
+
+	return typ == sessionTokenType
+}
+
+// claimTime returns the unix time held by the claim
+func claimTime(claims jwtgo.MapClaims, name string) (time.Time, bool) {
+	switch v := claims[name].(type) {
+	case float64:
+		return time.Unix(int64(v), 0), true
+	case int64:
+		return time.Unix(v, 0), true
+	case json.Number:
+		n, err := v.Int64()
+
+		return time.Unix(n, 0), err == nil
+	default:
+		return time.Time{}, false
+	}
+}
+
+// cookie builds the session cookie with the configured attributes
+func (m *SessionManager) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     m.name,
+		Value:    value,
+		Path:     m.path,
+		Domain:   m.domain,
+		MaxAge:   maxAge,
+		Secure:   !m.insecure,
+		HttpOnly: true,
+		SameSite: m.sameSite,
+	}
+}
+
+// seal encrypts the signed token when encryption is enabled
+func (m *SessionManager) seal(tokenStr string) (string, error) {
+	if m.aead == nil {
+		return tokenStr, nil
+	}
+
+	nonce := make([]byte, m.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("session: nonce: %w", err)
+	}
+
+	sealed := m.aead.Seal(nonce, nonce, []byte(tokenStr), []byte(m.name))
+
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts the cookie value when encryption is enabled
+func (m *SessionManager) open(value string) (string, error) {
+	if m.aead == nil {
+		return value, nil
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(sealed) < m.aead.NonceSize() {
+		return "", fmt.Errorf("session: decode: %w", ErrInvalidSession)
+	}
+
+	nonce, ciphertext := sealed[:m.aead.NonceSize()], sealed[m.aead.NonceSize():]
+
+	plain, err := m.aead.Open(nil, nonce, ciphertext, []byte(m.name))
+	if err != nil {
+		return "", fmt.Errorf("session: decrypt: %w", ErrInvalidSession)
+	}
+
+	return string(plain), nil
+}
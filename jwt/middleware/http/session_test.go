@@ -0,0 +1,254 @@
+package jwthttp
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/euforic/pkg-go/jwt"
+	jwtgo "github.com/golang-jwt/jwt"
+)
+
+func newTestJWT(t *testing.T) *jwt.JWT {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	return jwt.New(privateKey, &privateKey.PublicKey)
+}
+
+// newSessionToken signs the claims as a session token the way Issue does
+func newSessionToken(t *testing.T, j *jwt.JWT, ttl time.Duration, claims jwtgo.MapClaims) *jwt.Token {
+	t.Helper()
+
+	token, err := j.Create(ttl, claims)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	token.Header["typ"] = sessionTokenType
+
+	tokenStr, err := j.Sign(token)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	parsed, err := j.Parse(tokenStr, true)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	return parsed
+}
+
+func TestSessionManager(t *testing.T) {
+	j := newTestJWT(t)
+
+	tests := []struct {
+		name string
+		opts []SessionOpt
+	}{
+		{name: "Signed", opts: nil},
+		{name: "Encrypted", opts: []SessionOpt{WithEncryption(make([]byte, 32))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewSessionManager(j, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewSessionManager() error = %v", err)
+			}
+
+			rec := httptest.NewRecorder()
+			if err := m.Issue(rec, jwtgo.MapClaims{"sub": "1234567890"}); err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+
+			cookies := rec.Result().Cookies()
+			if len(cookies) != 1 {
+				t.Fatalf("Issue() set %d cookies, want 1", len(cookies))
+			}
+			c := cookies[0]
+			if !c.Secure || !c.HttpOnly || c.SameSite != http.SameSiteLaxMode {
+				t.Errorf("cookie attributes = %+v, want Secure, HttpOnly and SameSite=Lax", c)
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.AddCookie(c)
+
+			token, err := m.Read(r)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if sub := token.GetString("sub"); sub != "1234567890" {
+				t.Errorf("GetString(sub) = %q, want %q", sub, "1234567890")
+			}
+
+			tampered := httptest.NewRequest(http.MethodGet, "/", nil)
+			tampered.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value[:len(c.Value)-2]})
+			if _, err := m.Read(tampered); !errors.Is(err, ErrInvalidSession) {
+				t.Errorf("Read(tampered) error = %v, want %v", err, ErrInvalidSession)
+			}
+
+			if _, err := m.Read(httptest.NewRequest(http.MethodGet, "/", nil)); !errors.Is(err, ErrMissingSession) {
+				t.Errorf("Read(no cookie) error = %v, want %v", err, ErrMissingSession)
+			}
+		})
+	}
+}
+
+func TestSessionManager_Refresh(t *testing.T) {
+	j := newTestJWT(t)
+
+	m, err := NewSessionManager(j, WithSessionTTL(time.Hour))
+	if err != nil {
+		t.Fatalf("NewSessionManager() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		wantReset bool
+	}{
+		{name: "Fresh session", ttl: time.Hour, wantReset: false},
+		{name: "Half expired session", ttl: 10 * time.Minute, wantReset: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := newSessionToken(t, j, tt.ttl, jwtgo.MapClaims{SessionIDClaim: "sid-1", "sub": "abc"})
+
+			rec := httptest.NewRecorder()
+			if err := m.Refresh(rec, token); err != nil {
+				t.Fatalf("Refresh() error = %v", err)
+			}
+
+			if got := len(rec.Result().Cookies()) == 1; got != tt.wantReset {
+				t.Errorf("Refresh() reset cookie = %v, want %v", got, tt.wantReset)
+			}
+		})
+	}
+}
+
+func TestNewSessionManager_BadKey(t *testing.T) {
+	if _, err := NewSessionManager(nil, WithEncryption([]byte("short"))); err == nil {
+		t.Errorf("NewSessionManager() error = nil, want error")
+	}
+}
+
+func TestSessionManager_MaxAge(t *testing.T) {
+	j := newTestJWT(t)
+
+	m, err := NewSessionManager(j, WithSessionTTL(time.Hour), WithSessionMaxAge(2*time.Hour))
+	if err != nil {
+		t.Fatalf("NewSessionManager() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		authTime  time.Duration
+		ttl       time.Duration
+		wantErr   error
+		wantReset bool
+	}{
+		{name: "Within max age", authTime: -time.Hour, ttl: 10 * time.Minute, wantReset: true},
+		{name: "Expiry at max age", authTime: -time.Hour - 50*time.Minute, ttl: 10 * time.Minute, wantReset: false},
+		{name: "Past max age", authTime: -3 * time.Hour, ttl: 10 * time.Minute, wantErr: ErrSessionExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authTime := time.Now().Add(tt.authTime).Unix()
+			parsed := newSessionToken(t, j, tt.ttl, jwtgo.MapClaims{SessionIDClaim: "sid-1", AuthTimeClaim: authTime})
+
+			rec := httptest.NewRecorder()
+			if err := m.Refresh(rec, parsed); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Refresh() error = %v, want %v", err, tt.wantErr)
+			}
+
+			cookies := rec.Result().Cookies()
+			if got := len(cookies) == 1; got != tt.wantReset {
+				t.Fatalf("Refresh() reset cookie = %v, want %v", got, tt.wantReset)
+			}
+			if !tt.wantReset {
+				return
+			}
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.AddCookie(cookies[0])
+
+			token, err := m.Read(r)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if token.GetString(SessionIDClaim) != "sid-1" || int64(token.GetFloat64(AuthTimeClaim)) != authTime {
+				t.Errorf("refreshed claims = %v, want sid and auth time kept", token.Token.Claims)
+			}
+			if exp := int64(token.GetFloat64("exp")); exp > authTime+int64((2*time.Hour).Seconds()) {
+				t.Errorf("refreshed exp = %d, past max age", exp)
+			}
+		})
+	}
+}
+
+func TestSessionManager_Read(t *testing.T) {
+	j := newTestJWT(t)
+
+	m, err := NewSessionManager(j)
+	if err != nil {
+		t.Fatalf("NewSessionManager() error = %v", err)
+	}
+
+	bearer, err := j.CreatAndSign(time.Hour, jwtgo.MapClaims{"sub": "api"})
+	if err != nil {
+		t.Fatalf("CreatAndSign() error = %v", err)
+	}
+	bearerWithSID, err := j.CreatAndSign(time.Hour, jwtgo.MapClaims{"sub": "api", SessionIDClaim: "sid-1"})
+	if err != nil {
+		t.Fatalf("CreatAndSign() error = %v", err)
+	}
+	noSID, err := j.Sign(newSessionToken(t, j, time.Hour, jwtgo.MapClaims{"sub": "abc"}))
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	session, err := j.Sign(newSessionToken(t, j, time.Hour, jwtgo.MapClaims{"sub": "abc", SessionIDClaim: "sid-1"}))
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr error
+	}{
+		{name: "Session", value: session},
+		{name: "Bearer token", value: bearer, wantErr: ErrInvalidSession},
+		{name: "Bearer token with session id", value: bearerWithSID, wantErr: ErrInvalidSession},
+		{name: "Session without id", value: noSID, wantErr: ErrInvalidSession},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.AddCookie(&http.Cookie{Name: DefaultSessionCookie, Value: tt.value})
+
+			if _, err := m.Read(r); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Read() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	token, err := j.Parse(bearer, true)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if err := m.Refresh(httptest.NewRecorder(), token); !errors.Is(err, ErrInvalidSession) {
+		t.Errorf("Refresh(bearer token) error = %v, want %v", err, ErrInvalidSession)
+	}
+}
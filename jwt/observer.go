@@ -0,0 +1,221 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Op is the token operation an Event reports on
+type Op string
+
+const (
+	// OpCreate is reported when a token is created
+	OpCreate Op = "create"
+	// OpSign is reported when a token is signed
+	OpSign Op = "sign"
+	// OpParse is reported when a token is parsed
+	OpParse Op = "parse"
+	// OpAuthenticate is reported when a middleware authenticates a request
+	OpAuthenticate Op = "authenticate"
+)
+
+// Reason classifies the outcome of an operation
+type Reason string
+
+const (
+	// ReasonOK is reported when the operation succeeded
+	ReasonOK Reason = "ok"
+	// ReasonMissing is reported when no token was presented
+	ReasonMissing Reason = "missing"
+	// ReasonMalformed is reported when the token could not be decoded
+	ReasonMalformed Reason = "malformed"
+	// ReasonUnverifiable is reported when the token uses an unexpected algorithm or no key is available
+	ReasonUnverifiable Reason = "unverifiable"
+	// ReasonSignature is reported when the token signature does not match
+	ReasonSignature Reason = "signature"
+	// ReasonExpired is reported when the token is expired
+	ReasonExpired Reason = "expired"
+	// ReasonNotYetValid is reported when the token nbf or iat is in the future
+	ReasonNotYetValid Reason = "not_yet_valid"
+	// ReasonLimit is reported when the token exceeds the configured Limits or holds a rejected header
+	ReasonLimit Reason = "limit"
+	// ReasonInvalid is reported for any other failure
+	ReasonInvalid Reason = "invalid"
+)
+
+// Event describes the outcome of a token operation
+type Event struct {
+	Op     Op
+	Reason Reason
+	// Token is the token the operation worked on, if any
+	Token *Token
+	// Err is the error returned by the operation, nil on success
+	Err error
+}
+
+// Observer is notified of token operations. Implementations must be safe for
+// concurrent use.
+type Observer interface {
+	Observe(e Event)
+}
+
+// ObserverFunc adapts a function to the Observer interface
+type ObserverFunc func(e Event)
+
+// Observe calls f(e)
+func (f ObserverFunc) Observe(e Event) {
+	f(e)
+}
+
+// Observers fans events out to several observers
+type Observers []Observer
+
+// Observe calls Observe on every observer in order
+func (o Observers) Observe(e Event) {
+	for _, observer := range o {
+		observer.Observe(e)
+	}
+}
+
+// Classify maps an error returned while parsing a token to a Reason
+func Classify(err error) Reason {
+	if err == nil {
+		return ReasonOK
+	}
+
+	if errors.Is(err, ErrTokenLimit) || errors.Is(err, ErrTokenHeader) {
+		return ReasonLimit
+	}
+
+	var vErr *jwt.ValidationError
+	if !errors.As(err, &vErr) {
+		return ReasonInvalid
+	}
+
+	switch {
+	case vErr.Errors&jwt.ValidationErrorMalformed != 0:
+		return ReasonMalformed
+	case vErr.Errors&jwt.ValidationErrorUnverifiable != 0:
+		return ReasonUnverifiable
+	case vErr.Errors&jwt.ValidationErrorSignatureInvalid != 0:
+		return ReasonSignature
+	case vErr.Errors&jwt.ValidationErrorExpired != 0:
+		return ReasonExpired
+	case vErr.Errors&(jwt.ValidationErrorNotValidYet|jwt.ValidationErrorIssuedAt) != 0:
+		return ReasonNotYetValid
+	default:
+		return ReasonInvalid
+	}
+}
+
+// SlogObserver logs every event to a *slog.Logger. Successes are logged at
+// debug level and failures at warn level.
+type SlogObserver struct {
+	Logger *slog.Logger
+}
+
+// NewSlogObserver creates a new SlogObserver logging to logger
+func NewSlogObserver(logger *slog.Logger) *SlogObserver {
+	return &SlogObserver{Logger: logger}
+}
+
+// Observe logs the event
+func (o SlogObserver) Observe(e Event) {
+	level := slog.LevelDebug
+	attrs := []slog.Attr{
+		slog.String("op", string(e.Op)),
+		slog.String("reason", string(e.Reason)),
+	}
+
+	if e.Token != nil {
+		if sub := e.Token.GetString("sub"); sub != "" {
+			attrs = append(attrs, slog.String("sub", sub))
+		}
+		if kid, ok := e.Token.Header["kid"].(string); ok {
+			attrs = append(attrs, slog.String("kid", kid))
+		}
+	}
+
+	if e.Err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", e.Err.Error()))
+	}
+
+	o.Logger.LogAttrs(context.Background(), level, "jwt "+string(e.Op), attrs...)
+}
+
+// Counters counts events by operation and reason. It is backed by an
+// expvar.Map so it can be published on /debug/vars and can also be written
+// in the Prometheus text exposition format.
+type Counters struct {
+	m expvar.Map
+}
+
+// NewCounters creates a new, unpublished set of counters
+func NewCounters() *Counters {
+	return &Counters{}
+}
+
+// Publish publishes the counters under name in expvar. Like expvar.Publish it
+// panics if name is already registered.
+func (c *Counters) Publish(name string) {
+	expvar.Publish(name, &c.m)
+}
+
+// Observe increments the counter for the event operation and reason
+func (c *Counters) Observe(e Event) {
+	c.m.Add(counterKey(e.Op, e.Reason), 1)
+}
+
+// Get returns the current count for the operation and reason
+func (c *Counters) Get(op Op, reason Reason) int64 {
+	v, ok := c.m.Get(counterKey(op, reason)).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+
+	return v.Value()
+}
+
+// String returns the counters as JSON, implementing expvar.Var
+func (c *Counters) String() string {
+	return c.m.String()
+}
+
+// WriteTo writes the counters in the Prometheus text exposition format as
+// the jwt_operations_total counter
+func (c *Counters) WriteTo(w io.Writer) (int64, error) {
+	lines := []string{}
+	c.m.Do(func(kv expvar.KeyValue) {
+		op, reason, _ := strings.Cut(kv.Key, ".")
+		lines = append(lines, fmt.Sprintf("jwt_operations_total{op=%q,reason=%q} %s\n", op, reason, kv.Value.String()))
+	})
+	sort.Strings(lines)
+
+	var b strings.Builder
+	b.WriteString("# HELP jwt_operations_total Token operations by operation and reason.\n")
+	b.WriteString("# TYPE jwt_operations_total counter\n")
+	for _, line := range lines {
+		b.WriteString(line)
+	}
+
+	n, err := io.WriteString(w, b.String())
+	if err != nil {
+		return int64(n), fmt.Errorf("write counters: %w", err)
+	}
+
+	return int64(n), nil
+}
+
+// counterKey returns the expvar key for an operation and reason
+func counterKey(op Op, reason Reason) string {
+	return string(op) + "." + string(reason)
+}
@@ -0,0 +1,120 @@
+package jwt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func TestJWT_Observer(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	counters := NewCounters()
+	j := New(privateKey, &privateKey.PublicKey, WithObserver(counters))
+	other := New(otherKey, &otherKey.PublicKey)
+
+	valid, err := j.CreatAndSign(time.Hour, jwt.MapClaims{"sub": "abc"})
+	if err != nil {
+		t.Fatalf("CreatAndSign() error = %v", err)
+	}
+	expired, err := j.CreatAndSign(-time.Hour, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("CreatAndSign() error = %v", err)
+	}
+	wrongKey, err := other.CreatAndSign(time.Hour, jwt.MapClaims{})
+	if err != nil {
+		t.Fatalf("CreatAndSign() error = %v", err)
+	}
+	hs256, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{}).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		token      string
+		wantReason Reason
+	}{
+		{name: "Valid", token: valid, wantReason: ReasonOK},
+		{name: "Expired", token: expired, wantReason: ReasonExpired},
+		{name: "Wrong key", token: wrongKey, wantReason: ReasonSignature},
+		{name: "Unexpected algorithm", token: hs256, wantReason: ReasonUnverifiable},
+		{name: "Malformed", token: "not-a-token", wantReason: ReasonMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := counters.Get(OpParse, tt.wantReason)
+			_, _ = j.Parse(tt.token, true)
+			if got := counters.Get(OpParse, tt.wantReason) - before; got != 1 {
+				t.Errorf("Get(%s, %s) increased by %d, want 1", OpParse, tt.wantReason, got)
+			}
+		})
+	}
+
+	if got := counters.Get(OpCreate, ReasonOK); got != 2 {
+		t.Errorf("Get(%s, %s) = %d, want 2", OpCreate, ReasonOK, got)
+	}
+	if got := counters.Get(OpSign, ReasonOK); got != 2 {
+		t.Errorf("Get(%s, %s) = %d, want 2", OpSign, ReasonOK, got)
+	}
+
+	var b bytes.Buffer
+	if _, err := counters.WriteTo(&b); err != nil {
+		t.Fatalf("WriteTo() error = %v", err)
+	}
+	if want := `jwt_operations_total{op="parse",reason="expired"} 1`; !strings.Contains(b.String(), want) {
+		t.Errorf("WriteTo() = %q, want to contain %q", b.String(), want)
+	}
+}
+
+func TestSlogObserver(t *testing.T) {
+	var b bytes.Buffer
+	o := NewSlogObserver(slog.New(slog.NewTextHandler(&b, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	o.Observe(Event{Op: OpParse, Reason: ReasonExpired, Err: ErrTokenExpired})
+
+	for _, want := range []string{"level=WARN", "op=parse", "reason=expired", `error="token expired"`} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("Observe() logged %q, want to contain %q", b.String(), want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Reason
+	}{
+		{name: "Nil", err: nil, want: ReasonOK},
+		{name: "Token limit", err: fmt.Errorf("parse: %w", ErrTokenLimit), want: ReasonLimit},
+		{name: "Token header", err: fmt.Errorf("parse: %w", ErrTokenHeader), want: ReasonLimit},
+		{name: "Expired", err: &jwt.ValidationError{Errors: jwt.ValidationErrorExpired}, want: ReasonExpired},
+		{name: "Signature", err: fmt.Errorf("parse: %w", &jwt.ValidationError{Errors: jwt.ValidationErrorSignatureInvalid}), want: ReasonSignature},
+		{name: "Other", err: errors.New("boom"), want: ReasonInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.err); got != tt.want {
+				t.Errorf("Classify(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
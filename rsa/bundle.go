@@ -0,0 +1,246 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ErrKeyNotFound is returned when a bundle holds no key with the requested id
+var ErrKeyNotFound = errors.New("key not found in bundle")
+
+// Bundle is a collection of the keys and certificates read from a PEM bundle.
+// Keys are identified by the PublicKeyFingerprint of their public key.
+type Bundle struct {
+	// Keys holds every RSA public key in the bundle, including those of private keys and certificates
+	Keys map[string]*rsa.PublicKey
+	// PrivateKeys holds the RSA private keys in the bundle
+	PrivateKeys map[string]*rsa.PrivateKey
+	// Certificates holds the certificates in the order they were read
+	Certificates []*x509.Certificate
+}
+
+// NewBundle creates a new empty *Bundle
+func NewBundle() *Bundle {
+	return &Bundle{
+		Keys:        map[string]*rsa.PublicKey{},
+		PrivateKeys: map[string]*rsa.PrivateKey{},
+	}
+}
+
+// ReadBundle takes in a PEM bundle and decodes every private key, public key
+// and certificate block, skipping blocks of other types. The passphrase is
+// only used for encrypted private keys and may be nil, in which case the one
+// set with WithPassphrase is used. Blocks holding keys
+// other than RSA keys are rejected, certificates are kept whatever their key.
+func ReadBundle(reader io.Reader, passphrase []byte, opts ...ReadOpt) (*Bundle, error) {
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(reader); err != nil {
+		return nil, fmt.Errorf("error when reading bundle: %w", err)
+	}
+
+	o := newReadOptions(opts)
+	if passphrase == nil {
+		passphrase = o.passphrase
+	}
+
+	b := NewBundle()
+	found := false
+	rest := buf.Bytes()
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		ok, err := b.addBlock(block, passphrase, o)
+		if err != nil {
+			return nil, err
+		}
+		found = found || ok
+	}
+
+	if !found {
+		return nil, fmt.Errorf("failed to parse PEM block containing a key or certificate: %w", ErrFailedToParse)
+	}
+
+	return b, nil
+}
+
+// IDs returns the sorted ids of the public keys in the bundle
+func (b *Bundle) IDs() []string {
+	ids := make([]string, 0, len(b.Keys))
+	for id := range b.Keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+// Key returns the public key with the id, or ErrKeyNotFound
+func (b *Bundle) Key(id string) (*rsa.PublicKey, error) {
+	pub, ok := b.Keys[id]
+	if !ok {
+		return nil, fmt.Errorf("key %s: %w", id, ErrKeyNotFound)
+	}
+
+	return pub, nil
+}
+
+// Rsa returns the key with the id as an *Rsa, with the private key set when the bundle holds it
+func (b *Bundle) Rsa(id string) (*Rsa, error) {
+	pub, err := b.Key(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rsa{Private: b.PrivateKeys[id], Public: pub}, nil
+}
+
+// Certificate returns the first certificate holding the public key with the id, or ErrKeyNotFound
+func (b *Bundle) Certificate(id string) (*x509.Certificate, error) {
+	for _, cert := range b.Certificates {
+		pub, ok := cert.PublicKey.(*rsa.PublicKey)
+		if !ok {
+			continue
+		}
+
+		if fingerprint, err := PublicKeyFingerprint(pub); err == nil && fingerprint == id {
+			return cert, nil
+		}
+	}
+
+	return nil, fmt.Errorf("certificate for key %s: %w", id, ErrKeyNotFound)
+}
+
+// Verify hashes msg and verifies the signature with each key of the bundle in
+// turn, returning the id of the key that made it
+func (b *Bundle) Verify(msg, sig []byte, scheme Scheme, hash crypto.Hash) (string, error) {
+	digest, err := digest(hash, msg)
+	if err != nil {
+		return "", err
+	}
+
+	return b.verifyDigest(digest, sig, scheme, hash)
+}
+
+// VerifyDetached verifies a detached signature of everything read from src.
+// When the signature names its key only that key is tried, otherwise every key of the bundle is.
+func (b *Bundle) VerifyDetached(src io.Reader, sig *Signature) (string, error) {
+	if sig.KeyID != "" {
+		pub, err := b.Key(sig.KeyID)
+		if err != nil {
+			return "", err
+		}
+
+		if err := (Rsa{Public: pub}).VerifyDetached(src, sig); err != nil {
+			return "", err
+		}
+
+		return sig.KeyID, nil
+	}
+
+	digest, err := digestReader(sig.Hash, src)
+	if err != nil {
+		return "", err
+	}
+
+	return b.verifyDigest(digest, sig.Value, sig.Scheme, sig.Hash)
+}
+
+// verifyDigest verifies the signature of a digest with each key of the bundle in turn
+func (b *Bundle) verifyDigest(digest, sig []byte, scheme Scheme, hash crypto.Hash) (string, error) {
+	if len(b.Keys) == 0 {
+		return "", ErrNoPublicKey
+	}
+
+	for _, id := range b.IDs() {
+		err := Rsa{Public: b.Keys[id]}.verifyDigest(digest, sig, scheme, hash)
+		if err == nil {
+			return id, nil
+		}
+
+		if !errors.Is(err, ErrInvalidSignature) {
+			return "", err
+		}
+	}
+
+	return "", ErrInvalidSignature
+}
+
+// addBlock classifies a PEM block and adds its key or certificate to the bundle.
+// It reports false for blocks that hold neither.
+func (b *Bundle) addBlock(block *pem.Block, passphrase []byte, o readOptions) (bool, error) {
+	switch block.Type {
+	case pemTypeCertificate:
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return false, fmt.Errorf("failed to parse encoded certificate: %w", err)
+		}
+		b.Certificates = append(b.Certificates, cert)
+
+		if pub, ok := cert.PublicKey.(*rsa.PublicKey); ok {
+			return true, b.addPublicKey(pub, o)
+		}
+
+		return true, nil
+	case pemTypePublicKey, pemTypePKCS1PublicKey:
+		key, err := parsePublicKeyBlock(block)
+		if err != nil {
+			return false, err
+		}
+
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return false, fmt.Errorf("failed to parse encoded public key: %T: %w", key, ErrFailedToParse)
+		}
+
+		return true, b.addPublicKey(pub, o)
+	case pemTypePKCS1, pemTypePKCS8, pemTypeEncryptedPKCS8, pemTypeSEC1, pemTypeOpenSSH:
+		private, err := parsePrivateKeyBlock(block, passphrase)
+		if err != nil {
+			return false, err
+		}
+
+		if err := o.checkPrivateKey(private); err != nil {
+			return false, err
+		}
+
+		id, err := PublicKeyFingerprint(&private.PublicKey)
+		if err != nil {
+			return false, err
+		}
+		b.PrivateKeys[id] = private
+		b.Keys[id] = &private.PublicKey
+
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// addPublicKey checks a public key against the options and adds it to the bundle
+func (b *Bundle) addPublicKey(pub *rsa.PublicKey, o readOptions) error {
+	if err := o.checkPublicKey(pub); err != nil {
+		return err
+	}
+
+	id, err := PublicKeyFingerprint(pub)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := b.Keys[id]; !ok {
+		b.Keys[id] = pub
+	}
+
+	return nil
+}
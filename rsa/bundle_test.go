@@ -0,0 +1,122 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509/pkix"
+	"errors"
+	"testing"
+)
+
+func TestReadBundle(t *testing.T) {
+	first := New()
+	if err := first.GenerateWithOpts(WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+	second := New()
+	if err := second.GenerateWithOpts(WithKeySize(3072), WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+
+	ca, err := first.CreateCA(CertRequest{Subject: pkix.Name{CommonName: "bundle"}})
+	if err != nil {
+		t.Fatalf("CreateCA() error = %v", err)
+	}
+
+	sig, err := second.SignDetached(bytes.NewReader([]byte("message")), SchemePSS, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("SignDetached() error = %v", err)
+	}
+
+	var bundle bytes.Buffer
+	if err := first.WritePublicKey(&bundle); err != nil {
+		t.Fatalf("WritePublicKey() error = %v", err)
+	}
+	if err := WriteCertificates(&bundle, ca); err != nil {
+		t.Fatalf("WriteCertificates() error = %v", err)
+	}
+	if err := sig.Encode(&bundle); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if err := second.WriteEncryptedPrivateKey(&bundle, []byte("secret")); err != nil {
+		t.Fatalf("WriteEncryptedPrivateKey() error = %v", err)
+	}
+
+	firstID, _ := PublicKeyFingerprint(first.Public)
+	secondID, _ := PublicKeyFingerprint(second.Public)
+
+	b, err := ReadBundle(bytes.NewReader(bundle.Bytes()), []byte("secret"))
+	if err != nil {
+		t.Fatalf("ReadBundle() error = %v", err)
+	}
+	if got := len(b.Keys); got != 2 {
+		t.Errorf("len(Keys) = %d, want 2", got)
+	}
+	if got := len(b.Certificates); got != 1 {
+		t.Errorf("len(Certificates) = %d, want 1", got)
+	}
+	if _, err := b.Certificate(firstID); err != nil {
+		t.Errorf("Certificate() error = %v", err)
+	}
+	if r, err := b.Rsa(secondID); err != nil || r.Private == nil {
+		t.Errorf("Rsa() = %v, %v, want private key", r, err)
+	}
+	if r, err := b.Rsa(firstID); err != nil || r.Private != nil {
+		t.Errorf("Rsa() = %v, %v, want public key only", r, err)
+	}
+
+	unnamed := *sig
+	unnamed.KeyID = ""
+	unknown := *sig
+	unknown.KeyID = "unknown"
+	tampered := *sig
+	tampered.KeyID = ""
+	tampered.Value = append([]byte{}, sig.Value...)
+	tampered.Value[0] ^= 0xff
+
+	tests := []struct {
+		name    string
+		sig     Signature
+		wantID  string
+		wantErr error
+	}{
+		{name: "Named key", sig: *sig, wantID: secondID},
+		{name: "Any key", sig: unnamed, wantID: secondID},
+		{name: "Unknown key", sig: unknown, wantErr: ErrKeyNotFound},
+		{name: "Tampered", sig: tampered, wantErr: ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := b.VerifyDetached(bytes.NewReader([]byte("message")), &tt.sig)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("VerifyDetached() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if id != tt.wantID {
+				t.Errorf("VerifyDetached() id = %s, want %s", id, tt.wantID)
+			}
+		})
+	}
+
+	if _, err := ReadBundle(bytes.NewReader(bundle.Bytes()), nil); !errors.Is(err, ErrEncryptedKey) {
+		t.Errorf("ReadBundle(no passphrase) error = %v, want %v", err, ErrEncryptedKey)
+	}
+	if _, err := ReadBundle(bytes.NewReader(bundle.Bytes()), nil, WithPassphrase([]byte("secret"))); err != nil {
+		t.Errorf("ReadBundle(WithPassphrase) error = %v", err)
+	}
+	if _, err := ReadBundle(bytes.NewReader([]byte("no pem here")), nil); !errors.Is(err, ErrFailedToParse) {
+		t.Errorf("ReadBundle(empty) error = %v, want %v", err, ErrFailedToParse)
+	}
+
+	ec := NewEcdsa(nil)
+	if err := ec.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	var ecBundle bytes.Buffer
+	if err := ec.WritePublicKey(&ecBundle); err != nil {
+		t.Fatalf("WritePublicKey() error = %v", err)
+	}
+	if _, err := ReadBundle(&ecBundle, nil); !errors.Is(err, ErrFailedToParse) {
+		t.Errorf("ReadBundle(ecdsa) error = %v, want %v", err, ErrFailedToParse)
+	}
+}
@@ -0,0 +1,258 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/url"
+	"time"
+)
+
+// ErrNotCA is returned when signing with a certificate that is not a CA
+var ErrNotCA = errors.New("certificate is not a CA")
+
+const (
+	// pemTypeCSR is the PEM block type of certificate signing requests
+	pemTypeCSR = "CERTIFICATE REQUEST"
+	// DefaultCAValidity is the validity of CAs created without CertRequest.ValidFor
+	DefaultCAValidity = 10 * 365 * 24 * time.Hour
+	// DefaultLeafValidity is the validity of leaf certificates signed without CertRequest.ValidFor
+	DefaultLeafValidity = 365 * 24 * time.Hour
+	// serialBits is the size of random certificate serial numbers
+	serialBits = 128
+	// clockSkew backdates NotBefore to tolerate clocks running behind
+	clockSkew = 5 * time.Minute
+)
+
+// CertRequest describes the certificate, CA or CSR to create
+type CertRequest struct {
+	Subject        pkix.Name
+	DNSNames       []string
+	IPAddresses    []net.IP
+	EmailAddresses []string
+	URIs           []*url.URL
+	// NotBefore defaults to now minus a few minutes of clock skew
+	NotBefore time.Time
+	// ValidFor defaults to DefaultCAValidity or DefaultLeafValidity
+	ValidFor time.Duration
+	// KeyUsage defaults to cert and CRL signing for CAs and digital signature
+	// for leaf certificates, plus key encipherment for RSA leaf keys
+	KeyUsage x509.KeyUsage
+	// ExtKeyUsage defaults to server and client auth for leaf certificates
+	ExtKeyUsage []x509.ExtKeyUsage
+}
+
+// CreateCSR creates a certificate signing request for the private key and returns it DER encoded
+func (r Rsa) CreateCSR(req CertRequest) ([]byte, error) {
+	if r.Private == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:        req.Subject,
+		DNSNames:       req.DNSNames,
+		IPAddresses:    req.IPAddresses,
+		EmailAddresses: req.EmailAddresses,
+		URIs:           req.URIs,
+	}, r.Private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create csr: %w", err)
+	}
+
+	return der, nil
+}
+
+// CreateCA creates a self-signed CA certificate for the key pair
+func (r Rsa) CreateCA(req CertRequest) (*x509.Certificate, error) {
+	if r.Private == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	template, err := certTemplate(req, DefaultCAValidity)
+	if err != nil {
+		return nil, err
+	}
+
+	template.IsCA = true
+	template.BasicConstraintsValid = true
+	if req.KeyUsage == 0 {
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	}
+
+	return createCertificate(template, template, &r.Private.PublicKey, r.Private)
+}
+
+// SignCertificate signs a leaf certificate for the public key with the CA
+// certificate, r holding the CA private key. The leaf never outlives the CA:
+// its NotAfter is clamped to the NotAfter of the CA.
+func (r Rsa) SignCertificate(ca *x509.Certificate, pub crypto.PublicKey, req CertRequest) (*x509.Certificate, error) {
+	if r.Private == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	if !ca.IsCA {
+		return nil, ErrNotCA
+	}
+
+	template, err := certTemplate(req, DefaultLeafValidity)
+	if err != nil {
+		return nil, err
+	}
+
+	template.BasicConstraintsValid = true
+	if template.NotAfter.After(ca.NotAfter) {
+		template.NotAfter = ca.NotAfter
+	}
+	if req.KeyUsage == 0 {
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		if _, ok := pub.(*rsa.PublicKey); ok {
+			template.KeyUsage |= x509.KeyUsageKeyEncipherment
+		}
+	}
+	if len(req.ExtKeyUsage) == 0 {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	}
+
+	return createCertificate(template, ca, pub, r.Private)
+}
+
+// SignCSR checks the signature of the CSR and signs a leaf certificate for it
+// with the CA certificate. The subject and SANs are taken from the CSR, the
+// validity and key usages from req.
+func (r Rsa) SignCSR(ca *x509.Certificate, csr *x509.CertificateRequest, req CertRequest) (*x509.Certificate, error) {
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("failed to check csr signature: %w", err)
+	}
+
+	req.Subject = csr.Subject
+	req.DNSNames = csr.DNSNames
+	req.IPAddresses = csr.IPAddresses
+	req.EmailAddresses = csr.EmailAddresses
+	req.URIs = csr.URIs
+
+	return r.SignCertificate(ca, csr.PublicKey, req)
+}
+
+// WriteCSR encodes a DER encoded CSR to the io.Writer as a "CERTIFICATE REQUEST" block
+func WriteCSR(w io.Writer, der []byte) error {
+	if err := pem.Encode(w, &pem.Block{Type: pemTypeCSR, Bytes: der}); err != nil {
+		return fmt.Errorf("error when encode csr pem: %w", err)
+	}
+
+	return nil
+}
+
+// ReadCSR takes in the CSR bytes and decodes the certificate signing request
+func ReadCSR(reader io.Reader) (*x509.CertificateRequest, error) {
+	block, err := readPEM(reader, "csr")
+	if err != nil {
+		return nil, err
+	}
+
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse encoded csr: %w", err)
+	}
+
+	return csr, nil
+}
+
+// WriteCertificates encodes a certificate chain to the io.Writer as "CERTIFICATE" blocks, leaf first
+func WriteCertificates(w io.Writer, certs ...*x509.Certificate) error {
+	for _, cert := range certs {
+		if err := pem.Encode(w, &pem.Block{Type: pemTypeCertificate, Bytes: cert.Raw}); err != nil {
+			return fmt.Errorf("error when encode certificate pem: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ReadCertificates takes in a PEM certificate chain and decodes every
+// "CERTIFICATE" block, skipping blocks of other types
+func ReadCertificates(reader io.Reader) ([]*x509.Certificate, error) {
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(reader); err != nil {
+		return nil, fmt.Errorf("error when reading certificates: %w", err)
+	}
+
+	var certs []*x509.Certificate
+	rest := buf.Bytes()
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		if block.Type != pemTypeCertificate {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse encoded certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("failed to parse PEM block containing the certificate: %w", ErrFailedToParse)
+	}
+
+	return certs, nil
+}
+
+// certTemplate builds the certificate template shared by CAs and leaf certificates
+func certTemplate(req CertRequest, validity time.Duration) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), serialBits))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	notBefore := req.NotBefore
+	if notBefore.IsZero() {
+		notBefore = time.Now().Add(-clockSkew)
+	}
+
+	if req.ValidFor > 0 {
+		validity = req.ValidFor
+	}
+
+	return &x509.Certificate{
+		SerialNumber:   serial,
+		Subject:        req.Subject,
+		DNSNames:       req.DNSNames,
+		IPAddresses:    req.IPAddresses,
+		EmailAddresses: req.EmailAddresses,
+		URIs:           req.URIs,
+		NotBefore:      notBefore,
+		NotAfter:       notBefore.Add(validity),
+		KeyUsage:       req.KeyUsage,
+		ExtKeyUsage:    req.ExtKeyUsage,
+	}, nil
+}
+
+// createCertificate signs the template and parses the result
+func createCertificate(template, parent *x509.Certificate, pub crypto.PublicKey, signer crypto.Signer) (*x509.Certificate, error) {
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, signer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse created certificate: %w", err)
+	}
+
+	return cert, nil
+}
@@ -0,0 +1,141 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRsa_CertificateChain(t *testing.T) {
+	ca := New()
+	if err := ca.GenerateWithOpts(WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+	leaf := New()
+	if err := leaf.GenerateWithOpts(WithKeySize(3072), WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+
+	caCert, err := ca.CreateCA(CertRequest{Subject: pkix.Name{CommonName: "dev ca"}})
+	if err != nil {
+		t.Fatalf("CreateCA() error = %v", err)
+	}
+
+	csrDER, err := leaf.CreateCSR(CertRequest{
+		Subject:     pkix.Name{CommonName: "api"},
+		DNSNames:    []string{"api.local"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+	})
+	if err != nil {
+		t.Fatalf("CreateCSR() error = %v", err)
+	}
+
+	var csrPEM bytes.Buffer
+	if err := WriteCSR(&csrPEM, csrDER); err != nil {
+		t.Fatalf("WriteCSR() error = %v", err)
+	}
+
+	csr, err := ReadCSR(&csrPEM)
+	if err != nil {
+		t.Fatalf("ReadCSR() error = %v", err)
+	}
+
+	leafCert, err := ca.SignCSR(caCert, csr, CertRequest{})
+	if err != nil {
+		t.Fatalf("SignCSR() error = %v", err)
+	}
+
+	var chain bytes.Buffer
+	if err := WriteCertificates(&chain, leafCert, caCert); err != nil {
+		t.Fatalf("WriteCertificates() error = %v", err)
+	}
+
+	certs, err := ReadCertificates(&chain)
+	if err != nil {
+		t.Fatalf("ReadCertificates() error = %v", err)
+	}
+	if len(certs) != 2 {
+		t.Fatalf("ReadCertificates() returned %d certificates, want 2", len(certs))
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(certs[1])
+
+	tests := []struct {
+		name    string
+		dnsName string
+		wantErr bool
+	}{
+		{name: "DNS SAN", dnsName: "api.local"},
+		{name: "IP SAN", dnsName: "127.0.0.1"},
+		{name: "Unknown name", dnsName: "other.local", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := certs[0].Verify(x509.VerifyOptions{
+				DNSName:   tt.dnsName,
+				Roots:     roots,
+				KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Verify(%s) error = %v, wantErr %v", tt.dnsName, err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := leaf.SignCertificate(leafCert, ca.Public, CertRequest{}); !errors.Is(err, ErrNotCA) {
+		t.Errorf("SignCertificate(leaf as CA) error = %v, want %v", err, ErrNotCA)
+	}
+}
+
+func TestRsa_SignCertificate(t *testing.T) {
+	ca := New()
+	if err := ca.GenerateWithOpts(WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+
+	caCert, err := ca.CreateCA(CertRequest{Subject: pkix.Name{CommonName: "dev ca"}, ValidFor: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("CreateCA() error = %v", err)
+	}
+
+	ec := NewEcdsa(nil)
+	if err := ec.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		pub           crypto.PublicKey
+		validFor      time.Duration
+		wantUsage     x509.KeyUsage
+		wantNotAfter  time.Time
+		wantClampedCA bool
+	}{
+		{name: "RSA leaf", pub: ca.Public, validFor: time.Hour, wantUsage: x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment},
+		{name: "ECDSA leaf", pub: ec.Public, validFor: time.Hour, wantUsage: x509.KeyUsageDigitalSignature},
+		{name: "Outliving the CA", pub: ec.Public, validFor: 48 * time.Hour, wantUsage: x509.KeyUsageDigitalSignature, wantClampedCA: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert, err := ca.SignCertificate(caCert, tt.pub, CertRequest{Subject: pkix.Name{CommonName: "api"}, ValidFor: tt.validFor})
+			if err != nil {
+				t.Fatalf("SignCertificate() error = %v", err)
+			}
+
+			if cert.KeyUsage != tt.wantUsage {
+				t.Errorf("SignCertificate() KeyUsage = %v, want %v", cert.KeyUsage, tt.wantUsage)
+			}
+			if cert.NotAfter.After(caCert.NotAfter) || tt.wantClampedCA != cert.NotAfter.Equal(caCert.NotAfter) {
+				t.Errorf("SignCertificate() NotAfter = %s, CA NotAfter %s", cert.NotAfter, caCert.NotAfter)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrUnsupportedCurve is returned when an ECDSA key is not on P-256, P-384 or P-521
+var ErrUnsupportedCurve = errors.New("unsupported elliptic curve")
+
+// Ecdsa struct to hold the public and private ECDSA keys
+type Ecdsa struct {
+	Curve   elliptic.Curve
+	Private *ecdsa.PrivateKey
+	Public  *ecdsa.PublicKey
+}
+
+// NewEcdsa creates a new instance of *Ecdsa generating keys on the curve,
+// P-256 when curve is nil. Only P-256, P-384 and P-521 are supported.
+func NewEcdsa(curve elliptic.Curve) *Ecdsa {
+	if curve == nil {
+		curve = elliptic.P256()
+	}
+
+	return &Ecdsa{Curve: curve}
+}
+
+// Generate generates the public and private keys and sets them
+func (e *Ecdsa) Generate() error {
+	curve := e.Curve
+	if curve == nil {
+		curve = elliptic.P256()
+	}
+
+	if err := checkCurve(curve); err != nil {
+		return err
+	}
+
+	privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+	e.Curve = curve
+	e.Private = privateKey
+	e.Public = &privateKey.PublicKey
+
+	return nil
+}
+
+// WritePrivateKey encodes the private key to the io.Writer as a SEC 1 "EC PRIVATE KEY" block
+func (e Ecdsa) WritePrivateKey(w io.Writer) error {
+	if e.Private == nil {
+		return ErrNoPrivateKey
+	}
+
+	der, err := x509.MarshalECPrivateKey(e.Private)
+	if err != nil {
+		return fmt.Errorf("error when marshal private key: %w", err)
+	}
+
+	if err := pem.Encode(w, &pem.Block{Type: pemTypeSEC1, Bytes: der}); err != nil {
+		return fmt.Errorf("error when encode private pem: %w", err)
+	}
+
+	return nil
+}
+
+// ReadPrivateKey takes in the SEC 1 or PKCS#8 private key bytes and decodes and sets the private and public key
+func (e *Ecdsa) ReadPrivateKey(reader io.Reader) error {
+	block, err := readPEM(reader, "private")
+	if err != nil {
+		return err
+	}
+
+	key, err := parseSignerBlock(block, nil)
+	if err != nil {
+		return err
+	}
+
+	private, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return fmt.Errorf("failed to parse encoded private key: %T: %w", key, ErrFailedToParse)
+	}
+
+	if err := checkCurve(private.Curve); err != nil {
+		return err
+	}
+	e.Curve = private.Curve
+	e.Private = private
+	e.Public = &private.PublicKey
+
+	return nil
+}
+
+// WritePublicKey encodes the public key to the io.Writer as a PKIX "PUBLIC KEY" block
+func (e Ecdsa) WritePublicKey(w io.Writer) error {
+	if e.Public == nil {
+		return ErrNoPublicKey
+	}
+
+	return writePublicKey(w, e.Public)
+}
+
+// ReadPublicKey takes in the public key bytes and decodes and sets the public key only
+func (e *Ecdsa) ReadPublicKey(reader io.Reader) error {
+	block, err := readPEM(reader, "public")
+	if err != nil {
+		return err
+	}
+
+	pub, err := parsePublicKeyBlock(block)
+	if err != nil {
+		return err
+	}
+
+	key, ok := pub.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("failed to parse encoded public key: %T: %w", pub, ErrFailedToParse)
+	}
+
+	if err := checkCurve(key.Curve); err != nil {
+		return err
+	}
+	e.Curve = key.Curve
+	e.Public = key
+
+	return nil
+}
+
+// EcdsaPublicKeyFromBytes takes in the public key bytes and decodes the ECDSA public key only
+func EcdsaPublicKeyFromBytes(b []byte) (*ecdsa.PublicKey, error) {
+	e := NewEcdsa(nil)
+	if err := e.ReadPublicKey(bytes.NewReader(b)); err != nil {
+		return nil, err
+	}
+
+	return e.Public, nil
+}
+
+// checkCurve returns ErrUnsupportedCurve unless the curve is P-256, P-384 or P-521
+func checkCurve(curve elliptic.Curve) error {
+	switch curve {
+	case elliptic.P256(), elliptic.P384(), elliptic.P521():
+		return nil
+	default:
+		return fmt.Errorf("%s: %w", curve.Params().Name, ErrUnsupportedCurve)
+	}
+}
@@ -0,0 +1,114 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+)
+
+// Ed25519 struct to hold the public and private Ed25519 keys
+type Ed25519 struct {
+	Private ed25519.PrivateKey
+	Public  ed25519.PublicKey
+}
+
+// NewEd25519 creates a new instance of *Ed25519
+func NewEd25519() *Ed25519 {
+	return &Ed25519{}
+}
+
+// Generate generates the public and private keys and sets them
+func (e *Ed25519) Generate() error {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+	e.Private = privateKey
+	e.Public = publicKey
+
+	return nil
+}
+
+// WritePrivateKey encodes the private key to the io.Writer as a PKCS#8 "PRIVATE KEY" block
+func (e Ed25519) WritePrivateKey(w io.Writer) error {
+	if e.Private == nil {
+		return ErrNoPrivateKey
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(e.Private)
+	if err != nil {
+		return fmt.Errorf("error when marshal private key: %w", err)
+	}
+
+	if err := pem.Encode(w, &pem.Block{Type: pemTypePKCS8, Bytes: der}); err != nil {
+		return fmt.Errorf("error when encode private pem: %w", err)
+	}
+
+	return nil
+}
+
+// ReadPrivateKey takes in the PKCS#8 private key bytes and decodes and sets the private and public key
+func (e *Ed25519) ReadPrivateKey(reader io.Reader) error {
+	block, err := readPEM(reader, "private")
+	if err != nil {
+		return err
+	}
+
+	key, err := parseSignerBlock(block, nil)
+	if err != nil {
+		return err
+	}
+
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return fmt.Errorf("failed to parse encoded private key: %T: %w", key, ErrFailedToParse)
+	}
+	e.Private = private
+	e.Public, _ = private.Public().(ed25519.PublicKey)
+
+	return nil
+}
+
+// WritePublicKey encodes the public key to the io.Writer as a PKIX "PUBLIC KEY" block
+func (e Ed25519) WritePublicKey(w io.Writer) error {
+	if e.Public == nil {
+		return ErrNoPublicKey
+	}
+
+	return writePublicKey(w, e.Public)
+}
+
+// ReadPublicKey takes in the public key bytes and decodes and sets the public key only
+func (e *Ed25519) ReadPublicKey(reader io.Reader) error {
+	block, err := readPEM(reader, "public")
+	if err != nil {
+		return err
+	}
+
+	pub, err := parsePublicKeyBlock(block)
+	if err != nil {
+		return err
+	}
+
+	key, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return fmt.Errorf("failed to parse encoded public key: %T: %w", pub, ErrFailedToParse)
+	}
+	e.Public = key
+
+	return nil
+}
+
+// Ed25519PublicKeyFromBytes takes in the public key bytes and decodes the Ed25519 public key only
+func Ed25519PublicKeyFromBytes(b []byte) (ed25519.PublicKey, error) {
+	e := NewEd25519()
+	if err := e.ReadPublicKey(bytes.NewReader(b)); err != nil {
+		return nil, err
+	}
+
+	return e.Public, nil
+}
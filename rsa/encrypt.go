@@ -0,0 +1,309 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ErrInvalidCiphertext is returned when a ciphertext or envelope cannot be decrypted
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+const (
+	// dataKeySize is the size of the AES-256 data key wrapped in envelopes
+	dataKeySize = 32
+	// noncePrefixSize is the size of the random nonce prefix of streams
+	noncePrefixSize = 8
+	// streamChunkSize is the plaintext size of each stream chunk
+	streamChunkSize = 64 * 1024
+)
+
+//nolint:gochecknoglobals
+var (
+	// envelopeMagic starts every envelope produced by Seal
+	envelopeMagic = []byte("RSAE\x01")
+	// streamMagic starts every stream produced by EncryptStream
+	streamMagic = []byte("RSAS\x01")
+)
+
+// Encrypt encrypts msg with RSA-OAEP and SHA-256 using the public key. The
+// label may be nil and must be the same when decrypting. msg must be shorter
+// than the key size minus 66 bytes, use Seal for larger payloads.
+func (r Rsa) Encrypt(msg, label []byte) ([]byte, error) {
+	if r.Public == nil {
+		return nil, ErrNoPublicKey
+	}
+
+	out, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, r.Public, msg, label)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+
+	return out, nil
+}
+
+// Decrypt decrypts a ciphertext produced by Encrypt using the private key
+func (r Rsa) Decrypt(ciphertext, label []byte) ([]byte, error) {
+	if r.Private == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	out, err := rsa.DecryptOAEP(sha256.New(), nil, r.Private, ciphertext, label)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", ErrInvalidCiphertext)
+	}
+
+	return out, nil
+}
+
+// Seal encrypts a payload of any size into an envelope: a random AES-256-GCM
+// data key encrypts the payload and is itself wrapped with Encrypt
+func (r Rsa) Seal(plaintext, label []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	aead, err := r.writeEnvelopeHeader(&buf, envelopeMagic, label)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to read random: %w", err)
+	}
+	buf.Write(nonce)
+
+	return aead.Seal(buf.Bytes(), nonce, plaintext, envelopeMagic), nil
+}
+
+// Open decrypts an envelope produced by Seal
+func (r Rsa) Open(envelope, label []byte) ([]byte, error) {
+	src := bytes.NewReader(envelope)
+
+	aead, err := r.readEnvelopeHeader(src, envelopeMagic, label)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(src, nonce); err != nil {
+		return nil, fmt.Errorf("failed to read nonce: %w", ErrInvalidCiphertext)
+	}
+
+	ciphertext := envelope[len(envelope)-src.Len():]
+
+	out, err := aead.Open(nil, nonce, ciphertext, envelopeMagic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open envelope: %w", ErrInvalidCiphertext)
+	}
+
+	return out, nil
+}
+
+// EncryptStream encrypts src into w as a stream of AES-256-GCM chunks under a
+// data key wrapped with Encrypt. The last chunk is marked so that a truncated
+// stream is detected by DecryptStream.
+func (r Rsa) EncryptStream(w io.Writer, src io.Reader, label []byte) error {
+	aead, err := r.writeEnvelopeHeader(w, streamMagic, label)
+	if err != nil {
+		return err
+	}
+
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := io.ReadFull(rand.Reader, prefix); err != nil {
+		return fmt.Errorf("failed to read random: %w", err)
+	}
+	if _, err := w.Write(prefix); err != nil {
+		return fmt.Errorf("failed to write stream: %w", err)
+	}
+
+	current := make([]byte, streamChunkSize)
+	next := make([]byte, streamChunkSize)
+
+	n, err := readChunk(src, current)
+	if err != nil {
+		return err
+	}
+
+	for counter := uint32(0); ; counter++ {
+		m := 0
+		if n == streamChunkSize {
+			if m, err = readChunk(src, next); err != nil {
+				return err
+			}
+		}
+
+		final := m == 0
+		if err := writeChunk(w, aead, prefix, counter, current[:n], final); err != nil {
+			return err
+		}
+
+		if final {
+			return nil
+		}
+
+		if counter == math.MaxUint32 {
+			return fmt.Errorf("stream too long: %w", ErrInvalidCiphertext)
+		}
+
+		current, next, n = next, current, m
+	}
+}
+
+// DecryptStream decrypts a stream produced by EncryptStream from src into w.
+// Data is written as each chunk is authenticated, so on error w may already
+// hold a prefix of the plaintext.
+func (r Rsa) DecryptStream(w io.Writer, src io.Reader, label []byte) error {
+	aead, err := r.readEnvelopeHeader(src, streamMagic, label)
+	if err != nil {
+		return err
+	}
+
+	prefix := make([]byte, noncePrefixSize)
+	if _, err := io.ReadFull(src, prefix); err != nil {
+		return fmt.Errorf("failed to read nonce: %w", ErrInvalidCiphertext)
+	}
+
+	sealed := make([]byte, streamChunkSize+aead.Overhead())
+
+	for counter := uint32(0); ; counter++ {
+		var size uint32
+		if err := binary.Read(src, binary.BigEndian, &size); err != nil {
+			return fmt.Errorf("stream truncated: %w", ErrInvalidCiphertext)
+		}
+
+		if int(size) > len(sealed) {
+			return fmt.Errorf("chunk too large: %w", ErrInvalidCiphertext)
+		}
+
+		if _, err := io.ReadFull(src, sealed[:size]); err != nil {
+			return fmt.Errorf("stream truncated: %w", ErrInvalidCiphertext)
+		}
+
+		final := true
+		plain, err := aead.Open(nil, chunkNonce(prefix, counter), sealed[:size], []byte{1})
+		if err != nil {
+			final = false
+			if plain, err = aead.Open(nil, chunkNonce(prefix, counter), sealed[:size], []byte{0}); err != nil {
+				return fmt.Errorf("failed to open chunk: %w", ErrInvalidCiphertext)
+			}
+		}
+
+		if _, err := w.Write(plain); err != nil {
+			return fmt.Errorf("failed to write plaintext: %w", err)
+		}
+
+		if final {
+			return nil
+		}
+	}
+}
+
+// writeEnvelopeHeader writes the magic and a freshly wrapped data key and
+// returns the AEAD for the data key
+func (r Rsa) writeEnvelopeHeader(w io.Writer, magic, label []byte) (cipher.AEAD, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("failed to read random: %w", err)
+	}
+
+	wrapped, err := r.Encrypt(key, label)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, len(magic)+2+len(wrapped)) //nolint:mnd
+	header = append(header, magic...)
+	header = binary.BigEndian.AppendUint16(header, uint16(len(wrapped))) //nolint:gosec // bounded by the key size
+	header = append(header, wrapped...)
+
+	if _, err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write envelope: %w", err)
+	}
+
+	return newAEAD(key)
+}
+
+// readEnvelopeHeader reads the magic and the wrapped data key and returns the
+// AEAD for the unwrapped data key
+func (r Rsa) readEnvelopeHeader(src io.Reader, magic, label []byte) (cipher.AEAD, error) {
+	gotMagic := make([]byte, len(magic))
+	if _, err := io.ReadFull(src, gotMagic); err != nil || !bytes.Equal(gotMagic, magic) {
+		return nil, fmt.Errorf("unknown envelope format: %w", ErrInvalidCiphertext)
+	}
+
+	var size uint16
+	if err := binary.Read(src, binary.BigEndian, &size); err != nil {
+		return nil, fmt.Errorf("failed to read wrapped key: %w", ErrInvalidCiphertext)
+	}
+
+	wrapped := make([]byte, size)
+	if _, err := io.ReadFull(src, wrapped); err != nil {
+		return nil, fmt.Errorf("failed to read wrapped key: %w", ErrInvalidCiphertext)
+	}
+
+	key, err := r.Decrypt(wrapped, label)
+	if err != nil {
+		return nil, err
+	}
+
+	return newAEAD(key)
+}
+
+// newAEAD creates an AES-GCM AEAD for the data key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	return aead, nil
+}
+
+// readChunk fills chunk from src and returns the number of bytes read, which
+// is only less than len(chunk) at the end of src
+func readChunk(src io.Reader, chunk []byte) (int, error) {
+	n, err := io.ReadFull(src, chunk)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return 0, fmt.Errorf("failed to read plaintext: %w", err)
+	}
+
+	return n, nil
+}
+
+// writeChunk seals one chunk and writes it prefixed with its length. The
+// additional data marks whether the chunk is the last one.
+func writeChunk(w io.Writer, aead cipher.AEAD, prefix []byte, counter uint32, plain []byte, final bool) error {
+	ad := []byte{0}
+	if final {
+		ad[0] = 1
+	}
+
+	sealed := aead.Seal(nil, chunkNonce(prefix, counter), plain, ad)
+
+	out := binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(sealed)), uint32(len(sealed))) //nolint:gosec,mnd // bounded by the chunk size
+	out = append(out, sealed...)
+
+	if _, err := w.Write(out); err != nil {
+		return fmt.Errorf("failed to write stream: %w", err)
+	}
+
+	return nil
+}
+
+// chunkNonce builds the nonce of a chunk from the stream prefix and the chunk counter
+func chunkNonce(prefix []byte, counter uint32) []byte {
+	return binary.BigEndian.AppendUint32(append([]byte{}, prefix...), counter)
+}
@@ -0,0 +1,133 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func TestRsa_EncryptDecrypt(t *testing.T) {
+	r := New()
+	if err := r.GenerateWithOpts(WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+
+	msg := []byte("attack at dawn")
+
+	ciphertext, err := r.Encrypt(msg, []byte("label"))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	got, err := r.Decrypt(ciphertext, []byte("label"))
+	if err != nil {
+		t.Fatalf("Decrypt() error = %v", err)
+	}
+	if !bytes.Equal(got, msg) {
+		t.Errorf("Decrypt() = %q, want %q", got, msg)
+	}
+
+	if _, err := r.Decrypt(ciphertext, []byte("other")); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Errorf("Decrypt(wrong label) error = %v, want %v", err, ErrInvalidCiphertext)
+	}
+
+	if _, err := New().Encrypt(msg, nil); !errors.Is(err, ErrNoPublicKey) {
+		t.Errorf("Encrypt(no key) error = %v, want %v", err, ErrNoPublicKey)
+	}
+}
+
+func TestRsa_SealOpen(t *testing.T) {
+	r := New()
+	if err := r.GenerateWithOpts(WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "Empty", size: 0},
+		{name: "Small", size: 10},
+		{name: "Larger than modulus", size: 10000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := randomBytes(t, tt.size)
+
+			envelope, err := r.Seal(msg, nil)
+			if err != nil {
+				t.Fatalf("Seal() error = %v", err)
+			}
+
+			got, err := r.Open(envelope, nil)
+			if err != nil {
+				t.Fatalf("Open() error = %v", err)
+			}
+			if !bytes.Equal(got, msg) {
+				t.Errorf("Open() returned a different payload")
+			}
+
+			envelope[len(envelope)-1] ^= 1
+			if _, err := r.Open(envelope, nil); !errors.Is(err, ErrInvalidCiphertext) {
+				t.Errorf("Open(tampered) error = %v, want %v", err, ErrInvalidCiphertext)
+			}
+		})
+	}
+}
+
+func TestRsa_EncryptStream(t *testing.T) {
+	r := New()
+	if err := r.GenerateWithOpts(WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "Empty", size: 0},
+		{name: "Single chunk", size: 100},
+		{name: "Exact chunk", size: streamChunkSize},
+		{name: "Several chunks", size: 3*streamChunkSize + 17},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := randomBytes(t, tt.size)
+
+			var encrypted bytes.Buffer
+			if err := r.EncryptStream(&encrypted, bytes.NewReader(msg), nil); err != nil {
+				t.Fatalf("EncryptStream() error = %v", err)
+			}
+
+			var got bytes.Buffer
+			if err := r.DecryptStream(&got, bytes.NewReader(encrypted.Bytes()), nil); err != nil {
+				t.Fatalf("DecryptStream() error = %v", err)
+			}
+			if !bytes.Equal(got.Bytes(), msg) {
+				t.Errorf("DecryptStream() returned a different payload")
+			}
+
+			truncated := encrypted.Bytes()[:encrypted.Len()-1]
+			if err := r.DecryptStream(&bytes.Buffer{}, bytes.NewReader(truncated), nil); !errors.Is(err, ErrInvalidCiphertext) {
+				t.Errorf("DecryptStream(truncated) error = %v, want %v", err, ErrInvalidCiphertext)
+			}
+		})
+	}
+}
+
+// testKeys shares generated keys between the tests of the package
+var testKeys = NewKeyCache() //nolint:gochecknoglobals
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatalf("rand.Read() error = %v", err)
+	}
+
+	return b
+}
@@ -0,0 +1,351 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrUnsupportedFormat is returned when a key format is not supported
+var ErrUnsupportedFormat = errors.New("unsupported key format")
+
+// Format is a key encoding
+type Format string
+
+const (
+	// FormatPEM is PKIX "PUBLIC KEY" or PKCS#1 "RSA PRIVATE KEY" PEM
+	FormatPEM Format = "pem"
+	// FormatDER is PKIX DER for public keys and PKCS#8 DER for private keys
+	FormatDER Format = "der"
+	// FormatBase64URL is FormatDER encoded with unpadded base64url, for config files
+	FormatBase64URL Format = "base64url"
+	// FormatJWK is a JSON Web Key
+	FormatJWK Format = "jwk"
+	// FormatSSH is an OpenSSH authorized_keys line for public keys and an
+	// "OPENSSH PRIVATE KEY" PEM block for private keys
+	FormatSSH Format = "ssh"
+)
+
+// keyTypeRSA is the JWK key type of RSA keys
+const keyTypeRSA = "RSA"
+
+// JWK is an RSA JSON Web Key. The private members are only set for private keys.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	D   string `json:"d,omitempty"`
+	P   string `json:"p,omitempty"`
+	Q   string `json:"q,omitempty"`
+	Dp  string `json:"dp,omitempty"`
+	Dq  string `json:"dq,omitempty"`
+	Qi  string `json:"qi,omitempty"`
+}
+
+// EncodePublicKey encodes the public key in the format
+func (r Rsa) EncodePublicKey(format Format) ([]byte, error) {
+	if r.Public == nil {
+		return nil, ErrNoPublicKey
+	}
+
+	switch format {
+	case FormatPEM:
+		return encodePEM(r.WritePublicKey)
+	case FormatDER, FormatBase64URL:
+		der, err := x509.MarshalPKIXPublicKey(r.Public)
+		if err != nil {
+			return nil, fmt.Errorf("error when marshal public key: %w", err)
+		}
+
+		return encodeDER(der, format), nil
+	case FormatJWK:
+		return marshalJWK(publicJWK(r.Public))
+	case FormatSSH:
+		pub, err := ssh.NewPublicKey(r.Public)
+		if err != nil {
+			return nil, fmt.Errorf("error when marshal ssh public key: %w", err)
+		}
+
+		return ssh.MarshalAuthorizedKey(pub), nil
+	default:
+		return nil, fmt.Errorf("format %q: %w", format, ErrUnsupportedFormat)
+	}
+}
+
+// DecodePublicKey decodes a public key encoded in the format
+func DecodePublicKey(b []byte, format Format) (*rsa.PublicKey, error) {
+	var pub any
+	var err error
+
+	switch format {
+	case FormatPEM:
+		return PublicKeyFromBytes(b)
+	case FormatDER, FormatBase64URL:
+		var der []byte
+		if der, err = decodeDER(b, format); err == nil {
+			pub, err = x509.ParsePKIXPublicKey(der)
+		}
+	case FormatJWK:
+		var k JWK
+		if k, err = unmarshalJWK(b); err == nil {
+			return k.PublicKey()
+		}
+	case FormatSSH:
+		var sshPub ssh.PublicKey
+		if sshPub, _, _, _, err = ssh.ParseAuthorizedKey(b); err == nil {
+			if cryptoPub, ok := sshPub.(ssh.CryptoPublicKey); ok {
+				pub = cryptoPub.CryptoPublicKey()
+			}
+		}
+	default:
+		return nil, fmt.Errorf("format %q: %w", format, ErrUnsupportedFormat)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse encoded public key: %w", err)
+	}
+
+	key, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse encoded public key: %w", ErrFailedToParse)
+	}
+
+	return key, nil
+}
+
+// EncodePrivateKey encodes the private key in the format
+func (r Rsa) EncodePrivateKey(format Format) ([]byte, error) {
+	if r.Private == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	switch format {
+	case FormatPEM:
+		return encodePEM(r.WritePrivateKey)
+	case FormatDER, FormatBase64URL:
+		der, err := x509.MarshalPKCS8PrivateKey(r.Private)
+		if err != nil {
+			return nil, fmt.Errorf("error when marshal private key: %w", err)
+		}
+
+		return encodeDER(der, format), nil
+	case FormatJWK:
+		k, err := privateJWK(r.Private)
+		if err != nil {
+			return nil, err
+		}
+
+		return marshalJWK(k)
+	case FormatSSH:
+		block, err := ssh.MarshalPrivateKey(r.Private, "")
+		if err != nil {
+			return nil, fmt.Errorf("error when marshal ssh private key: %w", err)
+		}
+
+		return pem.EncodeToMemory(block), nil
+	default:
+		return nil, fmt.Errorf("format %q: %w", format, ErrUnsupportedFormat)
+	}
+}
+
+// DecodePrivateKey decodes a private key encoded in the format
+func DecodePrivateKey(b []byte, format Format) (*rsa.PrivateKey, error) {
+	var key any
+	var err error
+
+	switch format {
+	case FormatPEM:
+		r := New()
+		if err := r.ReadPrivateKey(bytes.NewReader(b)); err != nil {
+			return nil, err
+		}
+
+		return r.Private, nil
+	case FormatDER, FormatBase64URL:
+		var der []byte
+		if der, err = decodeDER(b, format); err == nil {
+			key, err = x509.ParsePKCS8PrivateKey(der)
+		}
+	case FormatJWK:
+		var k JWK
+		if k, err = unmarshalJWK(b); err == nil {
+			return k.PrivateKey()
+		}
+	case FormatSSH:
+		key, err = ssh.ParseRawPrivateKey(b)
+	default:
+		return nil, fmt.Errorf("format %q: %w", format, ErrUnsupportedFormat)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse encoded private key: %w", err)
+	}
+
+	private, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse encoded private key: %w", ErrFailedToParse)
+	}
+
+	return private, nil
+}
+
+// PublicKey decodes the JWK into an RSA public key
+func (k JWK) PublicKey() (*rsa.PublicKey, error) {
+	if k.Kty != keyTypeRSA {
+		return nil, fmt.Errorf("jwk key type %q: %w", k.Kty, ErrFailedToParse)
+	}
+
+	n, err := decodeJWKInt(k.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := decodeJWKInt(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("jwk exponent out of range: %w", ErrFailedToParse)
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+// PrivateKey decodes the JWK into an RSA private key
+func (k JWK) PrivateKey() (*rsa.PrivateKey, error) {
+	pub, err := k.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]*big.Int, 0, 3) //nolint:mnd
+	for _, s := range []string{k.D, k.P, k.Q} {
+		v, err := decodeJWKInt(s)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+
+	key := &rsa.PrivateKey{
+		PublicKey: *pub,
+		D:         values[0],
+		Primes:    []*big.Int{values[1], values[2]},
+	}
+	key.Precompute()
+
+	if err := key.Validate(); err != nil {
+		return nil, fmt.Errorf("failed to validate jwk private key: %w", err)
+	}
+
+	return key, nil
+}
+
+// publicJWK returns the JWK of a public key
+func publicJWK(pub *rsa.PublicKey) JWK {
+	return JWK{
+		Kty: keyTypeRSA,
+		N:   encodeJWKInt(pub.N),
+		E:   encodeJWKInt(big.NewInt(int64(pub.E))),
+	}
+}
+
+// privateJWK returns the JWK of a private key, which must have two primes as
+// multi-prime keys are not supported
+func privateJWK(private *rsa.PrivateKey) (JWK, error) {
+	if len(private.Primes) != 2 { //nolint:mnd
+		return JWK{}, fmt.Errorf("jwk of a key with %d primes: %w", len(private.Primes), ErrUnsupportedFormat)
+	}
+
+	private.Precompute()
+
+	k := publicJWK(&private.PublicKey)
+	k.D = encodeJWKInt(private.D)
+	k.P = encodeJWKInt(private.Primes[0])
+	k.Q = encodeJWKInt(private.Primes[1])
+	k.Dp = encodeJWKInt(private.Precomputed.Dp)
+	k.Dq = encodeJWKInt(private.Precomputed.Dq)
+	k.Qi = encodeJWKInt(private.Precomputed.Qinv)
+
+	return k, nil
+}
+
+// marshalJWK encodes a JWK as JSON
+func marshalJWK(k JWK) ([]byte, error) {
+	b, err := json.Marshal(k)
+	if err != nil {
+		return nil, fmt.Errorf("error when marshal jwk: %w", err)
+	}
+
+	return b, nil
+}
+
+// unmarshalJWK decodes a JWK from JSON
+func unmarshalJWK(b []byte) (JWK, error) {
+	var k JWK
+	if err := json.Unmarshal(b, &k); err != nil {
+		return k, fmt.Errorf("error when unmarshal jwk: %w", err)
+	}
+
+	return k, nil
+}
+
+// encodeJWKInt encodes a big integer as unpadded base64url
+func encodeJWKInt(v *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(v.Bytes())
+}
+
+// decodeJWKInt decodes a non-empty unpadded base64url big integer
+func decodeJWKInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("jwk integer %q: %w", s, ErrFailedToParse)
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+// encodePEM runs a PEM writer into memory
+func encodePEM(write func(w io.Writer) error) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// encodeDER returns der as is or base64url encoded
+func encodeDER(der []byte, format Format) []byte {
+	if format == FormatBase64URL {
+		return []byte(base64.RawURLEncoding.EncodeToString(der))
+	}
+
+	return der
+}
+
+// decodeDER returns b as is or base64url decoded, padded or not
+func decodeDER(b []byte, format Format) ([]byte, error) {
+	if format != FormatBase64URL {
+		return b, nil
+	}
+
+	der, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(string(b)), "="))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64url: %w", err)
+	}
+
+	return der, nil
+}
@@ -0,0 +1,93 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+)
+
+func TestRsa_EncodeDecodeFormats(t *testing.T) {
+	r := New()
+	if err := r.GenerateWithOpts(WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		format     Format
+		wantPrefix string
+	}{
+		{name: "PEM", format: FormatPEM, wantPrefix: "-----BEGIN"},
+		{name: "DER", format: FormatDER, wantPrefix: "\x30"},
+		{name: "Base64URL", format: FormatBase64URL, wantPrefix: "MI"},
+		{name: "JWK", format: FormatJWK, wantPrefix: `{"kty":"RSA"`},
+		{name: "SSH", format: FormatSSH},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pubBytes, err := r.EncodePublicKey(tt.format)
+			if err != nil {
+				t.Fatalf("EncodePublicKey() error = %v", err)
+			}
+			if !bytes.HasPrefix(pubBytes, []byte(tt.wantPrefix)) {
+				t.Errorf("EncodePublicKey() = %q, want prefix %q", pubBytes, tt.wantPrefix)
+			}
+
+			pub, err := DecodePublicKey(pubBytes, tt.format)
+			if err != nil {
+				t.Fatalf("DecodePublicKey() error = %v", err)
+			}
+			if !pub.Equal(r.Public) {
+				t.Errorf("DecodePublicKey() key does not match")
+			}
+
+			privBytes, err := r.EncodePrivateKey(tt.format)
+			if err != nil {
+				t.Fatalf("EncodePrivateKey() error = %v", err)
+			}
+
+			private, err := DecodePrivateKey(privBytes, tt.format)
+			if err != nil {
+				t.Fatalf("DecodePrivateKey() error = %v", err)
+			}
+			if !private.Equal(r.Private) {
+				t.Errorf("DecodePrivateKey() key does not match")
+			}
+		})
+	}
+
+	sshPub, err := r.EncodePublicKey(FormatSSH)
+	if err != nil {
+		t.Fatalf("EncodePublicKey(ssh) error = %v", err)
+	}
+	if !bytes.HasPrefix(sshPub, []byte("ssh-rsa ")) {
+		t.Errorf("EncodePublicKey(ssh) = %q, want an authorized_keys line", sshPub)
+	}
+
+	sshPriv, err := r.EncodePrivateKey(FormatSSH)
+	if err != nil {
+		t.Fatalf("EncodePrivateKey(ssh) error = %v", err)
+	}
+	if _, err := SignerFromBytes(sshPriv, nil); err != nil {
+		t.Errorf("SignerFromBytes(ssh) error = %v", err)
+	}
+
+	if _, err := r.EncodePublicKey(Format("xml")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("EncodePublicKey(xml) error = %v, want %v", err, ErrUnsupportedFormat)
+	}
+
+	multiPrime, err := rsa.GenerateMultiPrimeKey(rand.Reader, 3, 1536) //nolint:staticcheck
+	if err != nil {
+		t.Fatalf("GenerateMultiPrimeKey() error = %v", err)
+	}
+	if _, err := (Rsa{Private: multiPrime}).EncodePrivateKey(FormatJWK); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("EncodePrivateKey(multi-prime jwk) error = %v, want %v", err, ErrUnsupportedFormat)
+	}
+
+	if _, err := DecodePublicKey([]byte(`{"kty":"EC","n":"AQAB","e":"AQAB"}`), FormatJWK); !errors.Is(err, ErrFailedToParse) {
+		t.Errorf("DecodePublicKey(EC jwk) error = %v, want %v", err, ErrFailedToParse)
+	}
+}
@@ -0,0 +1,227 @@
+package rsa
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sync"
+)
+
+// ErrKeyTooSmall is returned when a key smaller than the minimum size is requested
+var ErrKeyTooSmall = errors.New("key size below minimum")
+
+const (
+	// DefaultKeySize is the key size used by Generate
+	DefaultKeySize = 2048
+	// MinKeySize is the default minimum key size accepted by GenerateWithOpts
+	MinKeySize = 2048
+	// publicExponent is the public exponent of generated keys
+	publicExponent = 65537
+	// primeRounds is the number of Miller-Rabin rounds run on prime candidates
+	primeRounds = 20
+	// keyCachePoolSize is the number of keys a KeyCache holds per key size
+	keyCachePoolSize = 3
+)
+
+// generateOptions holds the settings used by GenerateWithOpts
+type generateOptions struct {
+	size    int
+	minSize int
+	random  io.Reader
+	cache   *KeyCache
+	// deterministic builds the key from primes read directly from random
+	deterministic bool
+}
+
+// GenerateOpt is a functional option for GenerateWithOpts.
+type GenerateOpt func(*generateOptions)
+
+// WithKeySize sets the size of the generated key in bits.
+func WithKeySize(bits int) GenerateOpt {
+	return func(o *generateOptions) {
+		o.size = bits
+	}
+}
+
+// WithMinKeySize sets the minimum key size that may be generated.
+func WithMinKeySize(bits int) GenerateOpt {
+	return func(o *generateOptions) {
+		o.minSize = bits
+	}
+}
+
+// WithRandom sets the source of randomness passed to crypto/rsa, for example
+// a hardware random number generator.
+func WithRandom(random io.Reader) GenerateOpt {
+	return func(o *generateOptions) {
+		o.random = random
+		o.deterministic = false
+	}
+}
+
+// WithDeterministicRandom builds the key from primes read directly from
+// random. crypto/rsa deliberately does not produce the same key twice for the
+// same reader, while a seeded reader given to this option gives the same key
+// every time. It is meant for tests only; never use it for real keys.
+func WithDeterministicRandom(random io.Reader) GenerateOpt {
+	return func(o *generateOptions) {
+		o.random = random
+		o.deterministic = true
+	}
+}
+
+// WithKeyCache takes keys from the cache instead of generating a new key for
+// every call. It is meant for tests, which would otherwise spend most of
+// their time in key generation.
+func WithKeyCache(c *KeyCache) GenerateOpt {
+	return func(o *generateOptions) {
+		o.cache = c
+	}
+}
+
+// GenerateWithOpts generates the public and private keys using the options and sets them
+func (r *Rsa) GenerateWithOpts(opts ...GenerateOpt) error {
+	o := generateOptions{
+		size:    DefaultKeySize,
+		minSize: MinKeySize,
+		random:  rand.Reader,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.size < o.minSize {
+		return fmt.Errorf("failed to generate key: %d bits: %w", o.size, ErrKeyTooSmall)
+	}
+
+	var privateKey *rsa.PrivateKey
+	var err error
+	if o.cache != nil {
+		privateKey, err = o.cache.get(o.size, o.random, o.deterministic)
+	} else {
+		privateKey, err = generateKey(o.random, o.size, o.deterministic)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+
+	r.Private = privateKey
+	r.Public = &privateKey.PublicKey
+
+	return nil
+}
+
+// KeyCache holds a small pool of precomputed keys per key size and hands
+// them out in turn, so consecutive callers asking for the same size get
+// different keys while keys are reused across tests. It must only be used in
+// tests.
+type KeyCache struct {
+	mu   sync.Mutex
+	keys map[int][]*rsa.PrivateKey
+	next map[int]int
+}
+
+// NewKeyCache creates a new, empty key cache
+func NewKeyCache() *KeyCache {
+	return &KeyCache{keys: map[int][]*rsa.PrivateKey{}, next: map[int]int{}}
+}
+
+// get returns the next key of the pool for the size, generating keys until
+// the pool is full
+func (c *KeyCache) get(bits int, random io.Reader, deterministic bool) (*rsa.PrivateKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	i := c.next[bits]
+	c.next[bits] = (i + 1) % keyCachePoolSize
+
+	if i < len(c.keys[bits]) {
+		return c.keys[bits][i], nil
+	}
+
+	key, err := generateKey(random, bits, deterministic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate cached key: %w", err)
+	}
+	c.keys[bits] = append(c.keys[bits], key)
+
+	return key, nil
+}
+
+// generateKey generates a key with crypto/rsa, or deterministically from the
+// bytes of random when deterministic is set
+func generateKey(random io.Reader, bits int, deterministic bool) (*rsa.PrivateKey, error) {
+	if !deterministic {
+		return rsa.GenerateKey(random, bits) //nolint:wrapcheck
+	}
+
+	e := big.NewInt(publicExponent)
+	one := big.NewInt(1)
+
+	for {
+		p, err := readPrime(random, bits-bits/2, e) //nolint:mnd
+		if err != nil {
+			return nil, err
+		}
+
+		q, err := readPrime(random, bits/2, e) //nolint:mnd
+		if err != nil {
+			return nil, err
+		}
+
+		n := new(big.Int).Mul(p, q)
+		if p.Cmp(q) == 0 || n.BitLen() != bits {
+			continue
+		}
+
+		phi := new(big.Int).Mul(new(big.Int).Sub(p, one), new(big.Int).Sub(q, one))
+		d := new(big.Int).ModInverse(e, phi)
+		if d == nil {
+			continue
+		}
+
+		key := &rsa.PrivateKey{
+			PublicKey: rsa.PublicKey{N: n, E: publicExponent},
+			D:         d,
+			Primes:    []*big.Int{p, q},
+		}
+		key.Precompute()
+
+		if err := key.Validate(); err != nil {
+			return nil, fmt.Errorf("failed to validate key: %w", err)
+		}
+
+		return key, nil
+	}
+}
+
+// readPrime reads candidates from random until one is a prime of the given
+// size that is coprime with e
+func readPrime(random io.Reader, bits int, e *big.Int) (*big.Int, error) {
+	b := make([]byte, (bits+7)/8) //nolint:mnd
+	one := big.NewInt(1)
+
+	for {
+		if _, err := io.ReadFull(random, b); err != nil {
+			return nil, fmt.Errorf("failed to read random: %w", err)
+		}
+
+		// Clear bits above the size and set the top two bits so that the
+		// product of two primes has exactly twice the size.
+		excess := uint(len(b)*8 - bits) //nolint:mnd,gosec
+		b[0] &= byte(0xff >> excess)
+		p := new(big.Int).SetBytes(b)
+		p.SetBit(p, bits-1, 1)
+		p.SetBit(p, bits-2, 1) //nolint:mnd
+		p.SetBit(p, 0, 1)
+
+		pm1 := new(big.Int).Sub(p, one)
+		if p.ProbablyPrime(primeRounds) && new(big.Int).GCD(nil, nil, pm1, e).Cmp(one) == 0 {
+			return p, nil
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package rsa
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+)
+
+func TestRsa_GenerateWithOpts(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []GenerateOpt
+		wantBits int
+		wantErr  error
+	}{
+		{name: "Default size", opts: nil, wantBits: DefaultKeySize},
+		{name: "3072 bits", opts: []GenerateOpt{WithKeySize(3072)}, wantBits: 3072},
+		{name: "Below minimum", opts: []GenerateOpt{WithKeySize(1024)}, wantErr: ErrKeyTooSmall},
+		{name: "Lowered minimum", opts: []GenerateOpt{WithKeySize(1024), WithMinKeySize(1024)}, wantBits: 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			err := r.GenerateWithOpts(tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GenerateWithOpts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && r.Public.N.BitLen() != tt.wantBits {
+				t.Errorf("GenerateWithOpts() bits = %d, want %d", r.Public.N.BitLen(), tt.wantBits)
+			}
+		})
+	}
+}
+
+func TestRsa_GenerateWithSeededRandom(t *testing.T) {
+	a, b := New(), New()
+	for _, r := range []*Rsa{a, b} {
+		if err := r.GenerateWithOpts(WithDeterministicRandom(rand.New(rand.NewSource(1)))); err != nil { //nolint:gosec
+			t.Fatalf("GenerateWithOpts() error = %v", err)
+		}
+	}
+
+	if !a.Private.Equal(b.Private) {
+		t.Errorf("GenerateWithOpts() with the same seed generated different keys")
+	}
+}
+
+func TestRsa_GenerateWithKeyCache(t *testing.T) {
+	cache := NewKeyCache()
+
+	keys := make([]*Rsa, keyCachePoolSize+1)
+	for i := range keys {
+		keys[i] = New()
+		if err := keys[i].GenerateWithOpts(WithKeyCache(cache)); err != nil {
+			t.Fatalf("GenerateWithOpts() error = %v", err)
+		}
+	}
+
+	for i := 1; i < keyCachePoolSize; i++ {
+		if keys[i].Private.Equal(keys[i-1].Private) {
+			t.Errorf("GenerateWithOpts() with a cache returned key %d twice in a row", i-1)
+		}
+	}
+
+	if keys[keyCachePoolSize].Private != keys[0].Private {
+		t.Errorf("GenerateWithOpts() with a full cache generated a new key")
+	}
+}
@@ -1,3 +1,10 @@
 module github.com/euforic/pkg-go/rsa
 
 go 1.22.5
+
+require (
+	github.com/google/go-cmp v0.5.8
+	golang.org/x/crypto v0.22.0
+)
+
+require golang.org/x/sys v0.19.0 // indirect
@@ -0,0 +1,93 @@
+package rsa
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+)
+
+const (
+	// pemTypePublicKey is the PEM block type of PKIX public keys
+	pemTypePublicKey = "PUBLIC KEY"
+	// pemTypePKCS1PublicKey is the PEM block type of PKCS#1 RSA public keys
+	pemTypePKCS1PublicKey = "RSA PUBLIC KEY"
+	// pemTypeCertificate is the PEM block type of X.509 certificates
+	pemTypeCertificate = "CERTIFICATE"
+)
+
+// SignerFromBytes decodes an RSA, ECDSA or Ed25519 private key from any PKCS#1,
+// SEC 1, PKCS#8 or encrypted PKCS#8 PEM block. The passphrase is only used
+// for encrypted keys and may be nil.
+func SignerFromBytes(b, passphrase []byte) (crypto.Signer, error) { //nolint:ireturn
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("failed to parse PEM block containing the private key: %w", ErrFailedToParse)
+	}
+
+	return parseSignerBlock(block, passphrase)
+}
+
+// AnyPublicKeyFromBytes decodes an RSA, ECDSA or Ed25519 public key from a
+// PKIX or PKCS#1 public key block, or from the public key of a certificate
+func AnyPublicKeyFromBytes(b []byte) (crypto.PublicKey, error) { //nolint:ireturn
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("failed to parse PEM block containing the public key: %w", ErrFailedToParse)
+	}
+
+	return parsePublicKeyBlock(block)
+}
+
+// AnyPublicKeyFingerprint returns the hex encoded SHA-256 digest of the PKIX
+// encoding of an RSA, ECDSA or Ed25519 public key
+func AnyPublicKeyFingerprint(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("error when marshal public key: %w", err)
+	}
+
+	sum := sha256.Sum256(der)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// parsePublicKeyBlock parses a PKIX, PKCS#1 or certificate block into its public key
+func parsePublicKeyBlock(block *pem.Block) (crypto.PublicKey, error) { //nolint:ireturn
+	var pub crypto.PublicKey
+	var err error
+
+	switch block.Type {
+	case pemTypePKCS1PublicKey:
+		pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case pemTypeCertificate:
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			pub = cert.PublicKey
+		}
+	default:
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse encoded public key: %w", err)
+	}
+
+	return pub, nil
+}
+
+// writePublicKey encodes a public key to the io.Writer as a PKIX "PUBLIC KEY" block
+func writePublicKey(w io.Writer, pub crypto.PublicKey) error {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return fmt.Errorf("error when marshal public key: %w", err)
+	}
+
+	if err := pem.Encode(w, &pem.Block{Type: pemTypePublicKey, Bytes: der}); err != nil {
+		return fmt.Errorf("error when encode public pem: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// keyPair is the surface shared by Rsa, Ecdsa and Ed25519
+type keyPair interface {
+	Generate() error
+	WritePrivateKey(w io.Writer) error
+	WritePublicKey(w io.Writer) error
+}
+
+func TestKeyPairs(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      keyPair
+		wantType any
+	}{
+		{name: "RSA", key: New(), wantType: &rsa.PrivateKey{}},
+		{name: "ECDSA P-256", key: NewEcdsa(elliptic.P256()), wantType: &ecdsa.PrivateKey{}},
+		{name: "ECDSA P-384", key: NewEcdsa(elliptic.P384()), wantType: &ecdsa.PrivateKey{}},
+		{name: "Ed25519", key: NewEd25519(), wantType: ed25519.PrivateKey{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.key.Generate(); err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			var private, public bytes.Buffer
+			if err := tt.key.WritePrivateKey(&private); err != nil {
+				t.Fatalf("WritePrivateKey() error = %v", err)
+			}
+			if err := tt.key.WritePublicKey(&public); err != nil {
+				t.Fatalf("WritePublicKey() error = %v", err)
+			}
+
+			signer, err := SignerFromBytes(private.Bytes(), nil)
+			if err != nil {
+				t.Fatalf("SignerFromBytes() error = %v", err)
+			}
+			if got, want := typeName(signer), typeName(tt.wantType); got != want {
+				t.Errorf("SignerFromBytes() type = %s, want %s", got, want)
+			}
+
+			pub, err := AnyPublicKeyFromBytes(public.Bytes())
+			if err != nil {
+				t.Fatalf("AnyPublicKeyFromBytes() error = %v", err)
+			}
+
+			equal, ok := pub.(interface{ Equal(x crypto.PublicKey) bool })
+			if !ok || !equal.Equal(signer.Public()) {
+				t.Errorf("AnyPublicKeyFromBytes() does not match the private key")
+			}
+		})
+	}
+}
+
+func TestEcdsa_ReadPrivateKey(t *testing.T) {
+	e := NewEcdsa(elliptic.P384())
+	if err := e.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var private, public bytes.Buffer
+	if err := e.WritePrivateKey(&private); err != nil {
+		t.Fatalf("WritePrivateKey() error = %v", err)
+	}
+	if err := e.WritePublicKey(&public); err != nil {
+		t.Fatalf("WritePublicKey() error = %v", err)
+	}
+
+	got := NewEcdsa(nil)
+	if err := got.ReadPrivateKey(&private); err != nil {
+		t.Fatalf("ReadPrivateKey() error = %v", err)
+	}
+	if !got.Private.Equal(e.Private) || got.Curve != elliptic.P384() {
+		t.Errorf("ReadPrivateKey() key does not match")
+	}
+
+	pub, err := EcdsaPublicKeyFromBytes(public.Bytes())
+	if err != nil {
+		t.Fatalf("EcdsaPublicKeyFromBytes() error = %v", err)
+	}
+	if !pub.Equal(e.Public) {
+		t.Errorf("EcdsaPublicKeyFromBytes() key does not match")
+	}
+
+	if err := New().ReadPrivateKey(bytes.NewReader(private.Bytes())); err == nil {
+		t.Errorf("Rsa.ReadPrivateKey(ecdsa) error = nil, want error")
+	}
+}
+
+func TestEcdsa_UnsupportedCurve(t *testing.T) {
+	if err := NewEcdsa(elliptic.P224()).Generate(); !errors.Is(err, ErrUnsupportedCurve) {
+		t.Errorf("Generate(P-224) error = %v, want %v", err, ErrUnsupportedCurve)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	var private, public bytes.Buffer
+	if err := (Ecdsa{Private: key, Public: &key.PublicKey}).WritePrivateKey(&private); err != nil {
+		t.Fatalf("WritePrivateKey() error = %v", err)
+	}
+	if err := (Ecdsa{Private: key, Public: &key.PublicKey}).WritePublicKey(&public); err != nil {
+		t.Fatalf("WritePublicKey() error = %v", err)
+	}
+
+	if err := NewEcdsa(nil).ReadPrivateKey(&private); !errors.Is(err, ErrUnsupportedCurve) {
+		t.Errorf("ReadPrivateKey(P-224) error = %v, want %v", err, ErrUnsupportedCurve)
+	}
+	if _, err := EcdsaPublicKeyFromBytes(public.Bytes()); !errors.Is(err, ErrUnsupportedCurve) {
+		t.Errorf("EcdsaPublicKeyFromBytes(P-224) error = %v, want %v", err, ErrUnsupportedCurve)
+	}
+}
+
+func TestEd25519_ReadPrivateKey(t *testing.T) {
+	e := NewEd25519()
+	if err := e.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var private, public bytes.Buffer
+	if err := e.WritePrivateKey(&private); err != nil {
+		t.Fatalf("WritePrivateKey() error = %v", err)
+	}
+	if err := e.WritePublicKey(&public); err != nil {
+		t.Fatalf("WritePublicKey() error = %v", err)
+	}
+
+	got := NewEd25519()
+	if err := got.ReadPrivateKey(&private); err != nil {
+		t.Fatalf("ReadPrivateKey() error = %v", err)
+	}
+	if !got.Private.Equal(e.Private) || !got.Public.Equal(e.Public) {
+		t.Errorf("ReadPrivateKey() key does not match")
+	}
+
+	pub, err := Ed25519PublicKeyFromBytes(public.Bytes())
+	if err != nil {
+		t.Fatalf("Ed25519PublicKeyFromBytes() error = %v", err)
+	}
+	if !pub.Equal(e.Public) {
+		t.Errorf("Ed25519PublicKeyFromBytes() key does not match")
+	}
+}
+
+func typeName(v any) string {
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,370 @@
+package rsa
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInsecurePermissions is returned when a private key file is readable or writable by group or others
+	ErrInsecurePermissions = errors.New("private key file permissions are too open")
+	// ErrNoKey is returned when the keystore holds no key
+	ErrNoKey = errors.New("no key in keystore")
+)
+
+const (
+	// privateKeyFileMode is the mode of private key files written by the keystore
+	privateKeyFileMode fs.FileMode = 0o600
+	// keystoreDirMode is the mode of directories created by the keystore
+	keystoreDirMode fs.FileMode = 0o700
+	// insecureModeBits are the permission bits that must not be set on private key files
+	insecureModeBits fs.FileMode = 0o077
+)
+
+// WritePrivateKeyFile atomically writes the private key to path with 0600
+// permissions: the key is written to a temporary file in the same directory,
+// synced and renamed over path, so readers never see a partial key
+func WritePrivateKeyFile(path string, r Rsa) error {
+	var buf bytes.Buffer
+	if err := r.WritePrivateKey(&buf); err != nil {
+		return err
+	}
+
+	return WriteKeyFile(path, buf.Bytes())
+}
+
+// WriteKeyFile atomically writes encoded key material to path with 0600
+// permissions in the same way as WritePrivateKeyFile. An existing file at
+// path is replaced, its permissions are not kept.
+func WriteKeyFile(path string, data []byte) error {
+	return writeKeyFile(path, data, true)
+}
+
+// writeKeyFile writes data to a synced 0600 temporary file in the directory
+// of path and moves it into place. With replace the file is renamed over
+// path, otherwise it is hard linked to path, which like O_EXCL fails with
+// fs.ErrExist when path already exists, so no existing file is overwritten.
+func writeKeyFile(path string, data []byte, replace bool) error {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error when creating key file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	if err := tmp.Chmod(privateKeyFileMode); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("error when setting key file permissions: %w", err)
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("error when writing key file: %w", err)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+
+		return fmt.Errorf("error when syncing key file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error when closing key file: %w", err)
+	}
+
+	if replace {
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			return fmt.Errorf("error when renaming key file: %w", err)
+		}
+	} else if err := os.Link(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error when creating key file: %w", err)
+	}
+
+	return syncDir(dir)
+}
+
+// ReadPrivateKeyFile reads a private key file, refusing files that are
+// readable or writable by group or others
+func ReadPrivateKeyFile(path string, opts ...ReadOpt) (*Rsa, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error when opening key file: %w", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("error when reading key file: %w", err)
+	}
+
+	if runtime.GOOS != "windows" && info.Mode().Perm()&insecureModeBits != 0 {
+		return nil, fmt.Errorf("%s has mode %s: %w", path, info.Mode().Perm(), ErrInsecurePermissions)
+	}
+
+	r := New()
+	if err := r.ReadPrivateKey(f, opts...); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Keystore keeps a versioned history of private keys in a directory. Each
+// version is stored as <name>.v<version>.pem and the highest version is the
+// current key. Several processes may share the directory: a version file is
+// never overwritten once created.
+type Keystore struct {
+	dir  string
+	name string
+	opts []ReadOpt
+
+	mu      sync.RWMutex
+	current *Rsa
+	version int
+}
+
+// OpenKeystore opens the keystore for the named key in dir, creating dir with
+// 0700 permissions if needed, and loads the current key if there is one. The
+// options are used to read every key; with WithPassphrase saved keys are
+// also encrypted with the passphrase.
+func OpenKeystore(dir, name string, opts ...ReadOpt) (*Keystore, error) {
+	if err := os.MkdirAll(dir, keystoreDirMode); err != nil {
+		return nil, fmt.Errorf("error when creating keystore: %w", err)
+	}
+
+	k := &Keystore{dir: dir, name: name, opts: opts}
+	if _, _, err := k.Reload(); err != nil && !errors.Is(err, ErrNoKey) {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// Current returns the current key and its version, or ErrNoKey
+func (k *Keystore) Current() (*Rsa, int, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.current == nil {
+		return nil, 0, ErrNoKey
+	}
+
+	return k.current, k.version, nil
+}
+
+// Save stores the key as a new version and makes it the current key. When
+// another process saves the same version first, the next free version is used.
+func (k *Keystore) Save(r *Rsa) (int, error) {
+	var buf bytes.Buffer
+	if passphrase := newReadOptions(k.opts).passphrase; passphrase != nil {
+		if err := r.WriteEncryptedPrivateKey(&buf, passphrase); err != nil {
+			return 0, err
+		}
+	} else if err := r.WritePrivateKey(&buf); err != nil {
+		return 0, err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	versions, err := k.Versions()
+	if err != nil {
+		return 0, err
+	}
+
+	version := 1
+	if len(versions) > 0 {
+		version = versions[len(versions)-1] + 1
+	}
+
+	for {
+		err := writeKeyFile(k.path(version), buf.Bytes(), false)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return 0, err
+		}
+		version++
+	}
+
+	if version > k.version {
+		k.current, k.version = r, version
+	}
+
+	return version, nil
+}
+
+// Rotate generates a new key with the options and saves it as the current key
+func (k *Keystore) Rotate(opts ...GenerateOpt) (*Rsa, int, error) {
+	r := New()
+	if err := r.GenerateWithOpts(opts...); err != nil {
+		return nil, 0, err
+	}
+
+	version, err := k.Save(r)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return r, version, nil
+}
+
+// Load reads the given version of the key with the keystore options followed by opts
+func (k *Keystore) Load(version int, opts ...ReadOpt) (*Rsa, error) {
+	return ReadPrivateKeyFile(k.path(version), append(slices.Clip(k.opts), opts...)...)
+}
+
+// Reload reads the highest version from disk and makes it the current key.
+// It reports whether the current key changed.
+func (k *Keystore) Reload() (bool, int, error) {
+	versions, err := k.Versions()
+	if err != nil {
+		return false, 0, err
+	}
+
+	if len(versions) == 0 {
+		return false, 0, ErrNoKey
+	}
+
+	version := versions[len(versions)-1]
+
+	k.mu.RLock()
+	current, unchanged := k.version, k.current != nil && k.version >= version
+	k.mu.RUnlock()
+
+	if unchanged {
+		return false, current, nil
+	}
+
+	r, err := k.Load(version)
+	if err != nil {
+		return false, 0, err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	// A concurrent Save or Reload may have moved to a newer version meanwhile
+	if k.current != nil && k.version >= version {
+		return false, k.version, nil
+	}
+	k.current, k.version = r, version
+
+	return true, version, nil
+}
+
+// Versions returns the stored versions in ascending order
+func (k *Keystore) Versions() ([]int, error) {
+	entries, err := os.ReadDir(k.dir)
+	if err != nil {
+		return nil, fmt.Errorf("error when reading keystore: %w", err)
+	}
+
+	prefix := k.name + ".v"
+	versions := []int{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ".pem") {
+			continue
+		}
+
+		version, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".pem"))
+		if err != nil || version < 1 {
+			continue
+		}
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+
+	return versions, nil
+}
+
+// Prune removes all but the newest keep versions
+func (k *Keystore) Prune(keep int) error {
+	versions, err := k.Versions()
+	if err != nil {
+		return err
+	}
+
+	for len(versions) > keep && len(versions) > 0 {
+		if err := os.Remove(k.path(versions[0])); err != nil {
+			return fmt.Errorf("error when removing key version %d: %w", versions[0], err)
+		}
+		versions = versions[1:]
+	}
+
+	return nil
+}
+
+// Watch polls the directory every interval and reloads the current key when
+// a newer version appears, for example one written by another process
+// rotating the key. onChange is called with every newly loaded key. Watch
+// blocks until ctx is done; reload errors are passed to onError when it is
+// not nil and do not stop watching.
+func (k *Keystore) Watch(ctx context.Context, interval time.Duration, onChange func(r *Rsa, version int), onError func(err error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err() //nolint:wrapcheck
+		case <-ticker.C:
+			changed, _, err := k.Reload()
+			if err != nil {
+				if onError != nil && !errors.Is(err, ErrNoKey) {
+					onError(err)
+				}
+
+				continue
+			}
+
+			if changed && onChange != nil {
+				r, version, err := k.Current()
+				if err == nil {
+					onChange(r, version)
+				}
+			}
+		}
+	}
+}
+
+// path returns the file path of a version
+func (k *Keystore) path(version int) string {
+	return filepath.Join(k.dir, k.name+".v"+strconv.Itoa(version)+".pem")
+}
+
+// syncDir fsyncs a directory so that a rename in it is durable
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("error when opening key directory: %w", err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("error when syncing key directory: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,268 @@
+package rsa
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestKeystore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keys")
+
+	ks, err := OpenKeystore(dir, "signing")
+	if err != nil {
+		t.Fatalf("OpenKeystore() error = %v", err)
+	}
+
+	if _, _, err := ks.Current(); !errors.Is(err, ErrNoKey) {
+		t.Fatalf("Current() error = %v, want %v", err, ErrNoKey)
+	}
+
+	var previous *Rsa
+	for want := 1; want <= 3; want++ {
+		r, version, err := ks.Rotate(WithKeyCache(testKeys))
+		if err != nil {
+			t.Fatalf("Rotate() error = %v", err)
+		}
+		if version != want {
+			t.Errorf("Rotate() version = %d, want %d", version, want)
+		}
+
+		current, _, err := ks.Current()
+		if err != nil {
+			t.Fatalf("Current() error = %v", err)
+		}
+		loaded, err := ks.Load(version)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if !current.Private.Equal(r.Private) || !loaded.Private.Equal(r.Private) {
+			t.Errorf("Current() and Load() after Rotate() are not the rotated key")
+		}
+		if previous != nil && current.Private.Equal(previous.Private) {
+			t.Errorf("Rotate() to version %d kept the previous key", version)
+		}
+		previous = current
+	}
+
+	info, err := os.Stat(ks.path(3))
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Errorf("key file mode = %s, want %s", info.Mode().Perm(), os.FileMode(0o600))
+	}
+
+	if err := ks.Prune(2); err != nil {
+		t.Fatalf("Prune() error = %v", err)
+	}
+
+	versions, err := ks.Versions()
+	if err != nil {
+		t.Fatalf("Versions() error = %v", err)
+	}
+	if diff := cmp.Diff([]int{2, 3}, versions); diff != "" {
+		t.Errorf("Versions() mismatch (-want +got):\n%s", diff)
+	}
+
+	reopened, err := OpenKeystore(dir, "signing")
+	if err != nil {
+		t.Fatalf("OpenKeystore() error = %v", err)
+	}
+	if _, version, err := reopened.Current(); err != nil || version != 3 {
+		t.Errorf("Current() = %d, %v, want 3, nil", version, err)
+	}
+}
+
+func TestReadPrivateKeyFile_Permissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not checked on windows")
+	}
+
+	r := New()
+	if err := r.GenerateWithOpts(WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := WritePrivateKeyFile(path, *r); err != nil {
+		t.Fatalf("WritePrivateKeyFile() error = %v", err)
+	}
+
+	if _, err := ReadPrivateKeyFile(path); err != nil {
+		t.Fatalf("ReadPrivateKeyFile() error = %v", err)
+	}
+
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatalf("Chmod() error = %v", err)
+	}
+
+	if _, err := ReadPrivateKeyFile(path); !errors.Is(err, ErrInsecurePermissions) {
+		t.Errorf("ReadPrivateKeyFile() error = %v, want %v", err, ErrInsecurePermissions)
+	}
+}
+
+func TestKeystore_Watch(t *testing.T) {
+	dir := t.TempDir()
+
+	watcher, err := OpenKeystore(dir, "signing")
+	if err != nil {
+		t.Fatalf("OpenKeystore() error = %v", err)
+	}
+	rotator, err := OpenKeystore(dir, "signing")
+	if err != nil {
+		t.Fatalf("OpenKeystore() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	changed := make(chan int, 1)
+	go func() {
+		_ = watcher.Watch(ctx, 10*time.Millisecond, func(_ *Rsa, version int) {
+			changed <- version
+		}, nil)
+	}()
+
+	if _, _, err := rotator.Rotate(WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+
+	select {
+	case version := <-changed:
+		if version != 1 {
+			t.Errorf("Watch() reloaded version %d, want 1", version)
+		}
+	case <-ctx.Done():
+		t.Fatalf("Watch() did not reload the rotated key")
+	}
+}
+
+func TestKeystore_ConcurrentSave(t *testing.T) {
+	dir := t.TempDir()
+
+	r := New()
+	if err := r.GenerateWithOpts(WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+
+	const writers, saves = 4, 5
+
+	var wg sync.WaitGroup
+	saved := make(chan int, writers*saves)
+	for range writers {
+		ks, err := OpenKeystore(dir, "signing")
+		if err != nil {
+			t.Fatalf("OpenKeystore() error = %v", err)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for range saves {
+				version, err := ks.Save(r)
+				if err != nil {
+					t.Errorf("Save() error = %v", err)
+
+					return
+				}
+				saved <- version
+			}
+		}()
+	}
+	wg.Wait()
+	close(saved)
+
+	seen := map[int]bool{}
+	for version := range saved {
+		if seen[version] {
+			t.Errorf("Save() returned version %d twice", version)
+		}
+		seen[version] = true
+	}
+
+	ks, err := OpenKeystore(dir, "signing")
+	if err != nil {
+		t.Fatalf("OpenKeystore() error = %v", err)
+	}
+	versions, err := ks.Versions()
+	if err != nil {
+		t.Fatalf("Versions() error = %v", err)
+	}
+	if len(versions) != writers*saves || len(seen) != writers*saves {
+		t.Errorf("Versions() = %v, want %d versions", versions, writers*saves)
+	}
+}
+
+func TestKeystore_ReloadKeepsNewerVersion(t *testing.T) {
+	ks, err := OpenKeystore(t.TempDir(), "signing")
+	if err != nil {
+		t.Fatalf("OpenKeystore() error = %v", err)
+	}
+
+	for range 2 {
+		if _, _, err := ks.Rotate(WithKeyCache(testKeys)); err != nil {
+			t.Fatalf("Rotate() error = %v", err)
+		}
+	}
+
+	if err := os.Remove(ks.path(2)); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	changed, version, err := ks.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if _, current, _ := ks.Current(); changed || version != 2 || current != 2 {
+		t.Errorf("Reload() = %v, %d with current version %d, want false, 2, 2", changed, version, current)
+	}
+}
+
+func TestKeystore_Passphrase(t *testing.T) {
+	dir := t.TempDir()
+	passphrase := WithPassphrase([]byte("secret"))
+
+	ks, err := OpenKeystore(dir, "signing", passphrase)
+	if err != nil {
+		t.Fatalf("OpenKeystore() error = %v", err)
+	}
+
+	r, version, err := ks.Rotate(WithKeyCache(testKeys))
+	if err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+
+	b, err := os.ReadFile(ks.path(version))
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.HasPrefix(string(b), "-----BEGIN "+pemTypeEncryptedPKCS8) {
+		t.Errorf("key file = %q, want an encrypted key", b[:40])
+	}
+
+	if _, err := OpenKeystore(dir, "signing"); !errors.Is(err, ErrEncryptedKey) {
+		t.Errorf("OpenKeystore(no passphrase) error = %v, want %v", err, ErrEncryptedKey)
+	}
+
+	loaded, err := ks.Load(version)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !loaded.Private.Equal(r.Private) {
+		t.Errorf("Load() key does not match the rotated key")
+	}
+
+	if _, err := ks.Load(version, WithPassphrase([]byte("wrong"))); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Load(wrong passphrase) error = %v, want %v", err, ErrDecrypt)
+	}
+}
@@ -0,0 +1,488 @@
+package rsa
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // hmacWithSHA1 is the PKCS#5 default PRF and is only read
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/ssh"
+)
+
+var (
+	// ErrEncryptedKey is returned when reading an encrypted private key without a passphrase
+	ErrEncryptedKey = errors.New("private key is encrypted")
+	// ErrDecrypt is returned when an encrypted private key cannot be decrypted
+	ErrDecrypt = errors.New("failed to decrypt private key")
+	// ErrUnsupportedEncryption is returned when a private key uses an unsupported encryption scheme
+	ErrUnsupportedEncryption = errors.New("unsupported private key encryption")
+	// ErrKDFParams is returned when the key derivation parameters of an encrypted private key are out of bounds
+	ErrKDFParams = errors.New("key derivation parameters out of bounds")
+)
+
+const (
+	// pemTypePKCS1 is the PEM block type of PKCS#1 private keys
+	pemTypePKCS1 = "RSA PRIVATE KEY"
+	// pemTypePKCS8 is the PEM block type of PKCS#8 private keys
+	pemTypePKCS8 = "PRIVATE KEY"
+	// pemTypeSEC1 is the PEM block type of SEC 1 elliptic curve private keys
+	pemTypeSEC1 = "EC PRIVATE KEY"
+	// pemTypeOpenSSH is the PEM block type of OpenSSH private keys
+	pemTypeOpenSSH = "OPENSSH PRIVATE KEY"
+	// pemTypeEncryptedPKCS8 is the PEM block type of encrypted PKCS#8 private keys
+	pemTypeEncryptedPKCS8 = "ENCRYPTED PRIVATE KEY"
+
+	// pbkdf2Iterations is the PBKDF2 iteration count used when encrypting keys
+	pbkdf2Iterations = 600000
+	// saltSize is the size of the PBKDF2 salt used when encrypting keys
+	saltSize = 16
+	// aes256KeySize is the key size of AES-256
+	aes256KeySize = 32
+
+	// maxPBKDF2Iterations is the largest PBKDF2 iteration count accepted when decrypting keys
+	maxPBKDF2Iterations = 10_000_000
+	// maxScryptMemory is the most memory, 128 * N * r bytes, scrypt may use when decrypting keys
+	maxScryptMemory = 256 << 20
+	// maxScryptBlocks is the largest r * p accepted when decrypting keys
+	maxScryptBlocks = 64
+)
+
+//nolint:gochecknoglobals
+var (
+	oidPBES2      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidScrypt     = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11591, 4, 11}
+	oidHMACSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+// encryptedPrivateKeyInfo is the PKCS#8 EncryptedPrivateKeyInfo structure
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// pbes2Params is the PBES2-params structure of RFC 8018
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params is the PBKDF2-params structure of RFC 8018
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// scryptParams is the scrypt-params structure of RFC 7914
+type scryptParams struct {
+	Salt                     []byte
+	CostParameter            int
+	BlockSize                int
+	ParallelizationParameter int
+	KeyLength                int `asn1:"optional"`
+}
+
+// WritePrivateKeyPKCS8 encodes the rsa.Private key to the io.Writer as a PKCS#8 "PRIVATE KEY" block
+func (r Rsa) WritePrivateKeyPKCS8(w io.Writer) error {
+	if r.Private == nil {
+		return ErrNoPrivateKey
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(r.Private)
+	if err != nil {
+		return fmt.Errorf("error when marshal private key: %w", err)
+	}
+
+	if err := pem.Encode(w, &pem.Block{Type: pemTypePKCS8, Bytes: der}); err != nil {
+		return fmt.Errorf("error when encode private pem: %w", err)
+	}
+
+	return nil
+}
+
+// WriteEncryptedPrivateKey encodes the rsa.Private key to the io.Writer as an
+// "ENCRYPTED PRIVATE KEY" block, encrypted with PBES2 using PBKDF2-HMAC-SHA256
+// and AES-256-CBC as produced by `openssl pkcs8 -topk8 -v2 aes-256-cbc`
+func (r Rsa) WriteEncryptedPrivateKey(w io.Writer, passphrase []byte) error {
+	if r.Private == nil {
+		return ErrNoPrivateKey
+	}
+
+	return WriteEncryptedKey(w, r.Private, passphrase)
+}
+
+// WriteEncryptedKey encodes an RSA, ECDSA or Ed25519 private key to the io.Writer
+// as an "ENCRYPTED PRIVATE KEY" block, see WriteEncryptedPrivateKey
+func WriteEncryptedKey(w io.Writer, key crypto.Signer, passphrase []byte) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("error when marshal private key: %w", err)
+	}
+
+	encrypted, err := encryptPKCS8(der, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if err := pem.Encode(w, &pem.Block{Type: pemTypeEncryptedPKCS8, Bytes: encrypted}); err != nil {
+		return fmt.Errorf("error when encode private pem: %w", err)
+	}
+
+	return nil
+}
+
+// ReadEncryptedPrivateKey takes in the private key bytes, decrypts them with the
+// passphrase when they are encrypted and sets the private and public key. A
+// nil passphrase falls back to the one set with WithPassphrase.
+func (r *Rsa) ReadEncryptedPrivateKey(reader io.Reader, passphrase []byte, opts ...ReadOpt) error {
+	block, err := readPEM(reader, "private")
+	if err != nil {
+		return err
+	}
+
+	o := newReadOptions(opts)
+	if passphrase == nil {
+		passphrase = o.passphrase
+	}
+
+	private, err := parsePrivateKeyBlock(block, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if err := o.checkPrivateKey(private); err != nil {
+		return err
+	}
+	r.Private = private
+	r.Public = &r.Private.PublicKey
+
+	return nil
+}
+
+// parsePrivateKeyBlock parses a PKCS#1, PKCS#8 or encrypted PKCS#8 block holding an RSA key
+func parsePrivateKeyBlock(block *pem.Block, passphrase []byte) (*rsa.PrivateKey, error) {
+	key, err := parseSignerBlock(block, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	private, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse encoded private key: %T: %w", key, ErrFailedToParse)
+	}
+
+	return private, nil
+}
+
+// parseSignerBlock parses a PKCS#1, SEC 1, PKCS#8 or encrypted PKCS#8 block,
+// detecting the format from the block type
+func parseSignerBlock(block *pem.Block, passphrase []byte) (crypto.Signer, error) { //nolint:ireturn
+	der := block.Bytes
+
+	switch block.Type {
+	case pemTypePKCS1:
+		private, err := x509.ParsePKCS1PrivateKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse encoded private key: %w", err)
+		}
+
+		return private, nil
+	case pemTypeSEC1:
+		private, err := x509.ParseECPrivateKey(der)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse encoded private key: %w", err)
+		}
+
+		return private, nil
+	case pemTypeOpenSSH:
+		return parseOpenSSHBlock(block, passphrase)
+	case pemTypeEncryptedPKCS8:
+		if passphrase == nil {
+			return nil, ErrEncryptedKey
+		}
+
+		var err error
+		if der, err = decryptPKCS8(der, passphrase); err != nil {
+			return nil, err
+		}
+
+		if _, err := x509.ParsePKCS8PrivateKey(der); err != nil {
+			return nil, ErrDecrypt
+		}
+	case pemTypePKCS8:
+	default:
+		if private, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+			return private, nil
+		}
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse encoded private key: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse encoded private key: %T: %w", key, ErrFailedToParse)
+	}
+
+	return signer, nil
+}
+
+// parseOpenSSHBlock parses an "OPENSSH PRIVATE KEY" block, decrypting it with the passphrase when it is set
+func parseOpenSSHBlock(block *pem.Block, passphrase []byte) (crypto.Signer, error) { //nolint:ireturn
+	b := pem.EncodeToMemory(block)
+
+	var key any
+	var err error
+	if passphrase != nil {
+		key, err = ssh.ParseRawPrivateKeyWithPassphrase(b, passphrase)
+	} else {
+		key, err = ssh.ParseRawPrivateKey(b)
+	}
+
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		return nil, ErrEncryptedKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse encoded private key: %w", err)
+	}
+
+	if private, ok := key.(*ed25519.PrivateKey); ok {
+		return *private, nil
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse encoded private key: %T: %w", key, ErrFailedToParse)
+	}
+
+	return signer, nil
+}
+
+// encryptPKCS8 encrypts a PKCS#8 private key into an EncryptedPrivateKeyInfo
+func encryptPKCS8(der, passphrase []byte) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	iv := make([]byte, aes.BlockSize)
+	for _, b := range [][]byte{salt, iv} {
+		if _, err := io.ReadFull(rand.Reader, b); err != nil {
+			return nil, fmt.Errorf("failed to read random: %w", err)
+		}
+	}
+
+	key := pbkdf2.Key(passphrase, salt, pbkdf2Iterations, aes256KeySize, sha256.New)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	padding := aes.BlockSize - len(der)%aes.BlockSize
+	plain := make([]byte, len(der), len(der)+padding)
+	copy(plain, der)
+	for range padding {
+		plain = append(plain, byte(padding))
+	}
+
+	encrypted := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, plain)
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: pbkdf2Iterations,
+		PRF:            pkix.AlgorithmIdentifier{Algorithm: oidHMACSHA256, Parameters: asn1.NullRawValue},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal kdf params: %w", err)
+	}
+
+	ivParam, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal iv: %w", err)
+	}
+
+	params, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: kdfParams}},
+		EncryptionScheme:  pkix.AlgorithmIdentifier{Algorithm: oidAES256CBC, Parameters: asn1.RawValue{FullBytes: ivParam}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal pbes2 params: %w", err)
+	}
+
+	out, err := asn1.Marshal(encryptedPrivateKeyInfo{
+		Algorithm:     pkix.AlgorithmIdentifier{Algorithm: oidPBES2, Parameters: asn1.RawValue{FullBytes: params}},
+		EncryptedData: encrypted,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal encrypted private key: %w", err)
+	}
+
+	return out, nil
+}
+
+// decryptPKCS8 decrypts an EncryptedPrivateKeyInfo into a PKCS#8 private key
+func decryptPKCS8(der, passphrase []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse encrypted private key: %w", err)
+	}
+
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("algorithm %s: %w", info.Algorithm.Algorithm, ErrUnsupportedEncryption)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse pbes2 params: %w", err)
+	}
+
+	keySize, err := aesKeySize(params.EncryptionScheme.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil || len(iv) != aes.BlockSize {
+		return nil, fmt.Errorf("invalid iv: %w", ErrUnsupportedEncryption)
+	}
+
+	key, err := deriveKey(params.KeyDerivationFunc, passphrase, keySize)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	if len(info.EncryptedData) == 0 || len(info.EncryptedData)%aes.BlockSize != 0 {
+		return nil, ErrDecrypt
+	}
+
+	plain := make([]byte, len(info.EncryptedData))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, info.EncryptedData)
+
+	padding := int(plain[len(plain)-1])
+	if padding == 0 || padding > aes.BlockSize {
+		return nil, ErrDecrypt
+	}
+	for _, b := range plain[len(plain)-padding:] {
+		if int(b) != padding {
+			return nil, ErrDecrypt
+		}
+	}
+
+	return plain[:len(plain)-padding], nil
+}
+
+// deriveKey derives the encryption key with PBKDF2 or scrypt
+func deriveKey(kdf pkix.AlgorithmIdentifier, passphrase []byte, keySize int) ([]byte, error) {
+	switch {
+	case kdf.Algorithm.Equal(oidPBKDF2):
+		var params pbkdf2Params
+		if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &params); err != nil {
+			return nil, fmt.Errorf("failed to parse pbkdf2 params: %w", err)
+		}
+
+		if params.IterationCount < 1 || params.IterationCount > maxPBKDF2Iterations {
+			return nil, fmt.Errorf("pbkdf2 iteration count %d: %w", params.IterationCount, ErrKDFParams)
+		}
+
+		h, err := prfHash(params.PRF.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+
+		return pbkdf2.Key(passphrase, params.Salt, params.IterationCount, keySize, h), nil
+	case kdf.Algorithm.Equal(oidScrypt):
+		var params scryptParams
+		if _, err := asn1.Unmarshal(kdf.Parameters.FullBytes, &params); err != nil {
+			return nil, fmt.Errorf("failed to parse scrypt params: %w", err)
+		}
+
+		if err := checkScryptParams(params); err != nil {
+			return nil, err
+		}
+
+		key, err := scrypt.Key(passphrase, params.Salt, params.CostParameter, params.BlockSize, params.ParallelizationParameter, keySize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive key: %w", err)
+		}
+
+		return key, nil
+	default:
+		return nil, fmt.Errorf("key derivation %s: %w", kdf.Algorithm, ErrUnsupportedEncryption)
+	}
+}
+
+// checkScryptParams checks that N is a power of two and that the memory and
+// work scrypt needs for the parameters are bounded
+func checkScryptParams(params scryptParams) error {
+	n, r, p := params.CostParameter, params.BlockSize, params.ParallelizationParameter
+
+	switch {
+	case n <= 1 || n&(n-1) != 0:
+		return fmt.Errorf("scrypt cost %d is not a power of two: %w", n, ErrKDFParams)
+	case r < 1 || p < 1 || r > maxScryptBlocks/p:
+		return fmt.Errorf("scrypt block size %d and parallelization %d: %w", r, p, ErrKDFParams)
+	case n > maxScryptMemory/128/r: //nolint:mnd
+		return fmt.Errorf("scrypt cost %d with block size %d: %w", n, r, ErrKDFParams)
+	default:
+		return nil
+	}
+}
+
+// prfHash returns the hash of a PBKDF2 pseudo random function
+func prfHash(oid asn1.ObjectIdentifier) (func() hash.Hash, error) {
+	switch {
+	case len(oid) == 0, oid.Equal(oidHMACSHA1):
+		return sha1.New, nil
+	case oid.Equal(oidHMACSHA256):
+		return sha256.New, nil
+	case oid.Equal(oidHMACSHA384):
+		return sha512.New384, nil
+	case oid.Equal(oidHMACSHA512):
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("prf %s: %w", oid, ErrUnsupportedEncryption)
+	}
+}
+
+// aesKeySize returns the key size of an AES-CBC encryption scheme
+func aesKeySize(oid asn1.ObjectIdentifier) (int, error) {
+	switch {
+	case oid.Equal(oidAES128CBC):
+		return 16, nil //nolint:mnd
+	case oid.Equal(oidAES192CBC):
+		return 24, nil //nolint:mnd
+	case oid.Equal(oidAES256CBC):
+		return aes256KeySize, nil
+	default:
+		return 0, fmt.Errorf("encryption scheme %s: %w", oid, ErrUnsupportedEncryption)
+	}
+}
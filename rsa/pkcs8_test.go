@@ -0,0 +1,143 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestRsa_ReadPrivateKeyFormats(t *testing.T) {
+	want := New()
+	if err := want.ReadPrivateKey(mustOpen(t, "testdata/pkcs8.pem")); err != nil {
+		t.Fatalf("ReadPrivateKey(pkcs8) error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		file       string
+		passphrase []byte
+		opts       []ReadOpt
+		wantErr    error
+	}{
+		{name: "PKCS#8 without passphrase", file: "testdata/pkcs8.pem"},
+		{name: "PBKDF2 encrypted", file: "testdata/pkcs8_pbkdf2.pem", passphrase: []byte("secret")},
+		{name: "scrypt encrypted", file: "testdata/pkcs8_scrypt.pem", passphrase: []byte("secret")},
+		{name: "Wrong passphrase", file: "testdata/pkcs8_pbkdf2.pem", passphrase: []byte("wrong"), wantErr: ErrDecrypt},
+		{name: "Missing passphrase", file: "testdata/pkcs8_scrypt.pem", wantErr: ErrEncryptedKey},
+		{name: "Passphrase option", file: "testdata/pkcs8_scrypt.pem", opts: []ReadOpt{WithPassphrase([]byte("secret"))}},
+		{name: "Passphrase argument over option", file: "testdata/pkcs8_scrypt.pem", passphrase: []byte("secret"), opts: []ReadOpt{WithPassphrase([]byte("wrong"))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New()
+			err := got.ReadEncryptedPrivateKey(mustOpen(t, tt.file), tt.passphrase, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReadEncryptedPrivateKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !got.Private.Equal(want.Private) {
+				t.Errorf("ReadEncryptedPrivateKey() key does not match testdata/pkcs8.pem")
+			}
+		})
+	}
+}
+
+func TestRsa_WritePrivateKeyPKCS8(t *testing.T) {
+	r := New()
+	if err := r.Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var plain, pkcs1, encrypted bytes.Buffer
+	if err := r.WritePrivateKeyPKCS8(&plain); err != nil {
+		t.Fatalf("WritePrivateKeyPKCS8() error = %v", err)
+	}
+	if err := r.WritePrivateKey(&pkcs1); err != nil {
+		t.Fatalf("WritePrivateKey() error = %v", err)
+	}
+	if err := r.WriteEncryptedPrivateKey(&encrypted, []byte("secret")); err != nil {
+		t.Fatalf("WriteEncryptedPrivateKey() error = %v", err)
+	}
+
+	for name, b := range map[string][]byte{"pkcs8": plain.Bytes(), "pkcs1": pkcs1.Bytes()} {
+		got := New()
+		if err := got.ReadPrivateKey(bytes.NewReader(b)); err != nil {
+			t.Fatalf("ReadPrivateKey(%s) error = %v", name, err)
+		}
+		if !got.Private.Equal(r.Private) {
+			t.Errorf("ReadPrivateKey(%s) key does not match", name)
+		}
+	}
+
+	if err := New().ReadPrivateKey(bytes.NewReader(encrypted.Bytes())); !errors.Is(err, ErrEncryptedKey) {
+		t.Errorf("ReadPrivateKey(encrypted) error = %v, want %v", err, ErrEncryptedKey)
+	}
+
+	got := New()
+	if err := got.ReadEncryptedPrivateKey(bytes.NewReader(encrypted.Bytes()), []byte("secret")); err != nil {
+		t.Fatalf("ReadEncryptedPrivateKey() error = %v", err)
+	}
+	if !got.Private.Equal(r.Private) {
+		t.Errorf("ReadEncryptedPrivateKey() key does not match")
+	}
+}
+
+func TestDeriveKey_Bounds(t *testing.T) {
+	pbkdf2KDF := func(iterations int) pkix.AlgorithmIdentifier {
+		b, err := asn1.Marshal(pbkdf2Params{Salt: []byte("salt"), IterationCount: iterations})
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+
+		return pkix.AlgorithmIdentifier{Algorithm: oidPBKDF2, Parameters: asn1.RawValue{FullBytes: b}}
+	}
+	scryptKDF := func(n, r, p int) pkix.AlgorithmIdentifier {
+		b, err := asn1.Marshal(scryptParams{Salt: []byte("salt"), CostParameter: n, BlockSize: r, ParallelizationParameter: p})
+		if err != nil {
+			t.Fatalf("Marshal() error = %v", err)
+		}
+
+		return pkix.AlgorithmIdentifier{Algorithm: oidScrypt, Parameters: asn1.RawValue{FullBytes: b}}
+	}
+
+	tests := []struct {
+		name    string
+		kdf     pkix.AlgorithmIdentifier
+		wantErr bool
+	}{
+		{name: "PBKDF2", kdf: pbkdf2KDF(1000)},
+		{name: "PBKDF2 zero iterations", kdf: pbkdf2KDF(0), wantErr: true},
+		{name: "PBKDF2 negative iterations", kdf: pbkdf2KDF(-1), wantErr: true},
+		{name: "PBKDF2 too many iterations", kdf: pbkdf2KDF(maxPBKDF2Iterations + 1), wantErr: true},
+		{name: "scrypt", kdf: scryptKDF(1<<10, 8, 1)},
+		{name: "scrypt N not a power of two", kdf: scryptKDF(1000, 8, 1), wantErr: true},
+		{name: "scrypt N of one", kdf: scryptKDF(1, 8, 1), wantErr: true},
+		{name: "scrypt too much memory", kdf: scryptKDF(1<<20, 8, 1), wantErr: true},
+		{name: "scrypt r times p too large", kdf: scryptKDF(1<<10, 8, 1<<20), wantErr: true},
+		{name: "scrypt zero block size", kdf: scryptKDF(1<<10, 0, 1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := deriveKey(tt.kdf, []byte("secret"), aes256KeySize)
+			if tt.wantErr != errors.Is(err, ErrKDFParams) || (!tt.wantErr && err != nil) {
+				t.Errorf("deriveKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func mustOpen(t *testing.T, name string) *os.File {
+	t.Helper()
+
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatalf("Open(%s) error = %v", name, err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	return f
+}
@@ -2,7 +2,6 @@ package rsa
 
 import (
 	"bytes"
-	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
@@ -31,19 +30,10 @@ func New() *Rsa {
 	return &Rsa{}
 }
 
-// Generate gerates the public and private keys and sets them
+// Generate gerates the public and private keys and sets them.
+// It generates a DefaultKeySize key using crypto/rand, see GenerateWithOpts for other sizes.
 func (r *Rsa) Generate() error {
-	var err error
-	// generate key
-	recommendedKeySize := 2048
-	privateKey, err := rsa.GenerateKey(rand.Reader, recommendedKeySize)
-	if err != nil {
-		return fmt.Errorf("failed to generate key: %w", err)
-	}
-	r.Private = privateKey
-	r.Public = &privateKey.PublicKey
-
-	return nil
+	return r.GenerateWithOpts()
 }
 
 // WritePrivateKey encodes the rsa.Private key to the io.Writer
@@ -63,20 +53,24 @@ func (r Rsa) WritePrivateKey(w io.Writer) error {
 	return nil
 }
 
-// ReadPrivateKey takes in the private key bytes and decodes and sets the private and public key
-func (r *Rsa) ReadPrivateKey(reader io.Reader) error {
-	buf := new(bytes.Buffer)
-	if _, err := buf.ReadFrom(reader); err != nil {
-		return fmt.Errorf("error when reading private key: %w", err)
-	}
-	block, _ := pem.Decode(buf.Bytes())
-	if block == nil {
-		return fmt.Errorf("failed to parse PEM block containing the private key: %w", ErrFailedToParse)
+// ReadPrivateKey takes in the private key bytes and decodes and sets the private and public key.
+// PKCS#1 "RSA PRIVATE KEY" and PKCS#8 "PRIVATE KEY" blocks are detected from the block type,
+// encrypted keys are only read with the WithPassphrase option.
+func (r *Rsa) ReadPrivateKey(reader io.Reader, opts ...ReadOpt) error {
+	block, err := readPEM(reader, "private")
+	if err != nil {
+		return err
 	}
 
-	private, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	o := newReadOptions(opts)
+
+	private, err := parsePrivateKeyBlock(block, o.passphrase)
 	if err != nil {
-		return fmt.Errorf("failed to parse encoded private key: %w", err)
+		return err
+	}
+
+	if err := o.checkPrivateKey(private); err != nil {
+		return err
 	}
 	r.Private = private
 	r.Public = &r.Private.PublicKey
@@ -105,15 +99,10 @@ func (r Rsa) WritePublicKey(w io.Writer) error {
 }
 
 // ReadPrivateKey takes in the public key bytes and decodes and sets the public key only
-func (r *Rsa) ReadPublicKey(reader io.Reader) error {
-	buf := new(bytes.Buffer)
-	if _, err := buf.ReadFrom(reader); err != nil {
-		return fmt.Errorf("error when reading public key: %w", err)
-	}
-
-	block, _ := pem.Decode(buf.Bytes())
-	if block == nil {
-		return fmt.Errorf("failed to parse PEM block containing the public key: %w", ErrFailedToParse)
+func (r *Rsa) ReadPublicKey(reader io.Reader, opts ...ReadOpt) error {
+	block, err := readPEM(reader, "public")
+	if err != nil {
+		return err
 	}
 
 	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
@@ -121,17 +110,21 @@ func (r *Rsa) ReadPublicKey(reader io.Reader) error {
 		return fmt.Errorf("failed to parse encoded public key: %w", err)
 	}
 
-	var ok bool
-	r.Public, ok = pub.(*rsa.PublicKey)
+	key, ok := pub.(*rsa.PublicKey)
 	if !ok {
 		return fmt.Errorf("failed to parse encoded public key: %w", ErrFailedToParse)
 	}
 
+	if err := newReadOptions(opts).checkPublicKey(key); err != nil {
+		return err
+	}
+	r.Public = key
+
 	return nil
 }
 
 // PublicKeyFromBytes takes in the public key bytes and decodes the public key only
-func PublicKeyFromBytes(b []byte) (*rsa.PublicKey, error) {
+func PublicKeyFromBytes(b []byte, opts ...ReadOpt) (*rsa.PublicKey, error) {
 	block, _ := pem.Decode(b)
 	if block == nil {
 		return nil, fmt.Errorf("failed to parse PEM block containing the public key: %w", ErrFailedToParse)
@@ -148,5 +141,29 @@ func PublicKeyFromBytes(b []byte) (*rsa.PublicKey, error) {
 		return nil, fmt.Errorf("failed to parse encoded public key: %w", ErrFailedToParse)
 	}
 
+	if err := newReadOptions(opts).checkPublicKey(key); err != nil {
+		return nil, err
+	}
+
 	return key, nil
 }
+
+// PublicKeyFingerprint returns the hex encoded SHA-256 digest of the PKIX encoding of the public key
+func PublicKeyFingerprint(key *rsa.PublicKey) (string, error) {
+	return AnyPublicKeyFingerprint(key)
+}
+
+// readPEM reads all of reader and decodes the first PEM block
+func readPEM(reader io.Reader, kind string) (*pem.Block, error) {
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(reader); err != nil {
+		return nil, fmt.Errorf("error when reading %s key: %w", kind, err)
+	}
+
+	block, _ := pem.Decode(buf.Bytes())
+	if block == nil {
+		return nil, fmt.Errorf("failed to parse PEM block containing the %s key: %w", kind, ErrFailedToParse)
+	}
+
+	return block, nil
+}
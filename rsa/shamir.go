@@ -0,0 +1,288 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+var (
+	// ErrInvalidThreshold is returned when the share count or threshold is out of range
+	ErrInvalidThreshold = errors.New("invalid share count or threshold")
+	// ErrInvalidShare is returned when a share is corrupt or does not belong with the others
+	ErrInvalidShare = errors.New("invalid share")
+	// ErrNotEnoughShares is returned when fewer shares than the threshold are combined
+	ErrNotEnoughShares = errors.New("not enough shares")
+)
+
+const (
+	// pemTypeShare is the PEM block type of secret shares
+	pemTypeShare = "SECRET SHARE"
+	// shareHeaderIndex is the PEM header holding the share index
+	shareHeaderIndex = "Index"
+	// shareHeaderThreshold is the PEM header holding the number of shares needed
+	shareHeaderThreshold = "Threshold"
+	// shareHeaderSetID is the PEM header holding the id shared by all shares of a secret
+	shareHeaderSetID = "Set-Id"
+	// shareHeaderChecksum is the PEM header holding the share checksum
+	shareHeaderChecksum = "Checksum"
+	// maxShares is the largest number of shares, bounded by the non-zero elements of GF(256)
+	maxShares = 255
+	// setIDSize is the size of the random set id
+	setIDSize = 8
+	// checksumSize is the size of the truncated SHA-256 share checksum
+	checksumSize = 8
+)
+
+// Share is one share of a secret split with Shamir's secret sharing over GF(256)
+type Share struct {
+	// Index is the x coordinate of the share, from 1 to 255
+	Index byte
+	// Threshold is the number of shares needed to recover the secret
+	Threshold byte
+	// SetID is a random id shared by every share of the same split
+	SetID []byte
+	Value []byte
+}
+
+// Split splits the secret into n shares, any k of which recover it.
+// Fewer than k shares reveal nothing about the secret.
+func Split(secret []byte, n, k int) ([]Share, error) {
+	if k < 2 || k > n || n > maxShares {
+		return nil, fmt.Errorf("%d of %d: %w", k, n, ErrInvalidThreshold)
+	}
+
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("empty secret: %w", ErrInvalidShare)
+	}
+
+	setID := make([]byte, setIDSize)
+	if _, err := io.ReadFull(rand.Reader, setID); err != nil {
+		return nil, fmt.Errorf("failed to generate set id: %w", err)
+	}
+
+	shares := make([]Share, n)
+	for i := range shares {
+		shares[i] = Share{
+			Index:     byte(i + 1),
+			Threshold: byte(k),
+			SetID:     setID,
+			Value:     make([]byte, len(secret)),
+		}
+	}
+
+	coefficients := make([]byte, k)
+	defer clear(coefficients)
+	for pos, b := range secret {
+		coefficients[0] = b
+		if _, err := io.ReadFull(rand.Reader, coefficients[1:]); err != nil {
+			return nil, fmt.Errorf("failed to generate coefficients: %w", err)
+		}
+
+		for i := range shares {
+			shares[i].Value[pos] = gfEval(coefficients, shares[i].Index)
+		}
+	}
+
+	return shares, nil
+}
+
+// Combine recovers the secret from at least Threshold shares of the same split
+func Combine(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrNotEnoughShares
+	}
+
+	first := shares[0]
+	if int(first.Threshold) < 2 {
+		return nil, fmt.Errorf("threshold %d: %w", first.Threshold, ErrInvalidShare)
+	}
+
+	if len(shares) < int(first.Threshold) {
+		return nil, fmt.Errorf("have %d, need %d: %w", len(shares), first.Threshold, ErrNotEnoughShares)
+	}
+
+	seen := map[byte]bool{}
+	for _, s := range shares {
+		switch {
+		case s.Index == 0 || seen[s.Index]:
+			return nil, fmt.Errorf("share index %d: %w", s.Index, ErrInvalidShare)
+		case s.Threshold != first.Threshold || !bytes.Equal(s.SetID, first.SetID):
+			return nil, fmt.Errorf("share %d is from another split: %w", s.Index, ErrInvalidShare)
+		case len(s.Value) != len(first.Value) || len(s.Value) == 0:
+			return nil, fmt.Errorf("share %d length: %w", s.Index, ErrInvalidShare)
+		}
+		seen[s.Index] = true
+	}
+
+	// Lagrange interpolation at x = 0 over the first Threshold shares
+	shares = shares[:first.Threshold]
+	secret := make([]byte, len(first.Value))
+	for i, si := range shares {
+		basis := byte(1)
+		for j, sj := range shares {
+			if i != j {
+				basis = gfMul(basis, gfMul(sj.Index, gfInv(sj.Index^si.Index)))
+			}
+		}
+
+		for pos, y := range si.Value {
+			secret[pos] ^= gfMul(y, basis)
+		}
+	}
+
+	return secret, nil
+}
+
+// SplitPrivateKey splits the PKCS#1 encoding of the private key into n shares, any k of which recover it
+func (r Rsa) SplitPrivateKey(n, k int) ([]Share, error) {
+	if r.Private == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	der := x509.MarshalPKCS1PrivateKey(r.Private)
+	defer clear(der)
+
+	return Split(der, n, k)
+}
+
+// CombinePrivateKey recovers a private key split by SplitPrivateKey and sets the private and public key
+func (r *Rsa) CombinePrivateKey(shares []Share, opts ...ReadOpt) error {
+	der, err := Combine(shares)
+	if err != nil {
+		return err
+	}
+	defer clear(der)
+
+	private, err := x509.ParsePKCS1PrivateKey(der)
+	if err != nil {
+		return fmt.Errorf("failed to parse combined private key: %w: %w", err, ErrInvalidShare)
+	}
+
+	if err := newReadOptions(opts).checkPrivateKey(private); err != nil {
+		return err
+	}
+	r.Private = private
+	r.Public = &r.Private.PublicKey
+
+	return nil
+}
+
+// Encode writes the share as a "SECRET SHARE" PEM block whose headers hold
+// the index, threshold, set id and a checksum of the share
+func (s Share) Encode(w io.Writer) error {
+	block := &pem.Block{
+		Type: pemTypeShare,
+		Headers: map[string]string{
+			shareHeaderIndex:     strconv.Itoa(int(s.Index)),
+			shareHeaderThreshold: strconv.Itoa(int(s.Threshold)),
+			shareHeaderSetID:     hex.EncodeToString(s.SetID),
+			shareHeaderChecksum:  hex.EncodeToString(s.checksum()),
+		},
+		Bytes: s.Value,
+	}
+
+	if err := pem.Encode(w, block); err != nil {
+		return fmt.Errorf("error when encode share pem: %w", err)
+	}
+
+	return nil
+}
+
+// DecodeShare reads a share written by Share.Encode and verifies its checksum
+func DecodeShare(reader io.Reader) (*Share, error) {
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(reader); err != nil {
+		return nil, fmt.Errorf("error when reading share: %w", err)
+	}
+
+	block, _ := pem.Decode(buf.Bytes())
+	if block == nil || block.Type != pemTypeShare {
+		return nil, fmt.Errorf("failed to parse PEM block containing the share: %w", ErrFailedToParse)
+	}
+
+	index, err := strconv.ParseUint(block.Headers[shareHeaderIndex], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("index: %w: %w", err, ErrInvalidShare)
+	}
+
+	threshold, err := strconv.ParseUint(block.Headers[shareHeaderThreshold], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("threshold: %w: %w", err, ErrInvalidShare)
+	}
+
+	setID, err := hex.DecodeString(block.Headers[shareHeaderSetID])
+	if err != nil {
+		return nil, fmt.Errorf("set id: %w: %w", err, ErrInvalidShare)
+	}
+
+	checksum, err := hex.DecodeString(block.Headers[shareHeaderChecksum])
+	if err != nil {
+		return nil, fmt.Errorf("checksum: %w: %w", err, ErrInvalidShare)
+	}
+
+	s := &Share{
+		Index:     byte(index),
+		Threshold: byte(threshold),
+		SetID:     setID,
+		Value:     block.Bytes,
+	}
+
+	if subtle.ConstantTimeCompare(checksum, s.checksum()) != 1 {
+		return nil, fmt.Errorf("share %d checksum mismatch: %w", s.Index, ErrInvalidShare)
+	}
+
+	return s, nil
+}
+
+// checksum returns the truncated SHA-256 digest of the share fields
+func (s Share) checksum() []byte {
+	h := sha256.New()
+	h.Write([]byte{s.Index, s.Threshold, byte(len(s.SetID))})
+	h.Write(s.SetID)
+	h.Write(s.Value)
+
+	return h.Sum(nil)[:checksumSize]
+}
+
+// gfEval evaluates the polynomial with the coefficients, lowest degree first, at x
+func gfEval(coefficients []byte, x byte) byte {
+	var y byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ coefficients[i]
+	}
+
+	return y
+}
+
+// gfMul multiplies in GF(256) with the AES reduction polynomial x^8 + x^4 + x^3 + x + 1,
+// without branching on the operands
+func gfMul(a, b byte) byte {
+	var p byte
+	for range 8 {
+		p ^= a & -(b & 1)
+		a = a<<1 ^ 0x1b&-(a>>7)
+		b >>= 1
+	}
+
+	return p
+}
+
+// gfInv returns the multiplicative inverse in GF(256) as a^254
+func gfInv(a byte) byte {
+	result := byte(1)
+	for range 7 {
+		a = gfMul(a, a)
+		result = gfMul(result, a)
+	}
+
+	return result
+}
@@ -0,0 +1,98 @@
+package rsa
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestSplitCombine(t *testing.T) {
+	secret := randomBytes(t, 100)
+
+	shares, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+
+	other, err := Split(secret, 5, 3)
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+
+	duplicate := []Share{shares[0], shares[1], shares[1]}
+
+	tests := []struct {
+		name    string
+		shares  []Share
+		wantErr error
+	}{
+		{name: "Threshold shares", shares: []Share{shares[0], shares[2], shares[4]}},
+		{name: "All shares", shares: shares},
+		{name: "Shares out of order", shares: []Share{shares[3], shares[1], shares[0]}},
+		{name: "Too few shares", shares: shares[:2], wantErr: ErrNotEnoughShares},
+		{name: "No shares", shares: nil, wantErr: ErrNotEnoughShares},
+		{name: "Duplicate share", shares: duplicate, wantErr: ErrInvalidShare},
+		{name: "Mixed splits", shares: []Share{shares[0], shares[1], other[2]}, wantErr: ErrInvalidShare},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Combine(tt.shares)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Combine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !bytes.Equal(got, secret) {
+				t.Errorf("Combine() = %x, want %x", got, secret)
+			}
+		})
+	}
+
+	for _, nk := range [][2]int{{3, 1}, {2, 3}, {256, 2}} {
+		if _, err := Split(secret, nk[0], nk[1]); !errors.Is(err, ErrInvalidThreshold) {
+			t.Errorf("Split(%d, %d) error = %v, want %v", nk[0], nk[1], err, ErrInvalidThreshold)
+		}
+	}
+}
+
+func TestShare_EncodeDecode(t *testing.T) {
+	r := New()
+	if err := r.GenerateWithOpts(WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+
+	shares, err := r.SplitPrivateKey(3, 2)
+	if err != nil {
+		t.Fatalf("SplitPrivateKey() error = %v", err)
+	}
+
+	decoded := make([]Share, 0, 2)
+	for _, s := range shares[1:] {
+		var buf bytes.Buffer
+		if err := s.Encode(&buf); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+
+		got, err := DecodeShare(&buf)
+		if err != nil {
+			t.Fatalf("DecodeShare() error = %v", err)
+		}
+		decoded = append(decoded, *got)
+	}
+
+	got := New()
+	if err := got.CombinePrivateKey(decoded, WithPolicy(DefaultPolicy())); err != nil {
+		t.Fatalf("CombinePrivateKey() error = %v", err)
+	}
+	if !got.Private.Equal(r.Private) {
+		t.Errorf("CombinePrivateKey() recovered a different key")
+	}
+
+	var buf bytes.Buffer
+	if err := shares[0].Encode(&buf); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	corrupt := bytes.Replace(buf.Bytes(), []byte("Index: 1"), []byte("Index: 2"), 1)
+	if _, err := DecodeShare(bytes.NewReader(corrupt)); !errors.Is(err, ErrInvalidShare) {
+		t.Errorf("DecodeShare(corrupt) error = %v, want %v", err, ErrInvalidShare)
+	}
+}
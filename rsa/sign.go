@@ -0,0 +1,254 @@
+package rsa
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha512" // register SHA-384 and SHA-512
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrInvalidSignature is returned when a signature does not verify
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrUnsupportedScheme is returned when a signature scheme is not supported
+	ErrUnsupportedScheme = errors.New("unsupported signature scheme")
+	// ErrUnsupportedHash is returned when a hash function is not supported
+	ErrUnsupportedHash = errors.New("unsupported hash")
+)
+
+// Scheme is an RSA signature scheme
+type Scheme string
+
+const (
+	// SchemePSS is RSASSA-PSS with a salt as long as the hash
+	SchemePSS Scheme = "PSS"
+	// SchemePKCS1v15 is RSASSA-PKCS1-v1_5
+	SchemePKCS1v15 Scheme = "PKCS1v15"
+)
+
+const (
+	// pemTypeSignature is the PEM block type of detached signatures
+	pemTypeSignature = "RSA SIGNATURE"
+	// signatureHeaderScheme is the PEM header holding the signature scheme
+	signatureHeaderScheme = "Scheme"
+	// signatureHeaderHash is the PEM header holding the hash function
+	signatureHeaderHash = "Hash"
+	// signatureHeaderKeyID is the PEM header holding the signing key fingerprint
+	signatureHeaderKeyID = "Key-Id"
+)
+
+// supportedHashes are the hash functions accepted for signing, by name
+var supportedHashes = map[string]crypto.Hash{ //nolint:gochecknoglobals
+	crypto.SHA256.String(): crypto.SHA256,
+	crypto.SHA384.String(): crypto.SHA384,
+	crypto.SHA512.String(): crypto.SHA512,
+}
+
+// Sign hashes msg and signs the digest with the private key
+func (r Rsa) Sign(msg []byte, scheme Scheme, hash crypto.Hash) ([]byte, error) {
+	digest, err := digest(hash, msg)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.signDigest(digest, scheme, hash)
+}
+
+// Verify hashes msg and verifies the signature with the public key
+func (r Rsa) Verify(msg, sig []byte, scheme Scheme, hash crypto.Hash) error {
+	digest, err := digest(hash, msg)
+	if err != nil {
+		return err
+	}
+
+	return r.verifyDigest(digest, sig, scheme, hash)
+}
+
+// SignReader hashes everything read from src and signs the digest with the private key
+func (r Rsa) SignReader(src io.Reader, scheme Scheme, hash crypto.Hash) ([]byte, error) {
+	digest, err := digestReader(hash, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.signDigest(digest, scheme, hash)
+}
+
+// VerifyReader hashes everything read from src and verifies the signature with the public key
+func (r Rsa) VerifyReader(src io.Reader, sig []byte, scheme Scheme, hash crypto.Hash) error {
+	digest, err := digestReader(hash, src)
+	if err != nil {
+		return err
+	}
+
+	return r.verifyDigest(digest, sig, scheme, hash)
+}
+
+// Signature is a detached signature, for example of a release artifact
+type Signature struct {
+	Scheme Scheme
+	Hash   crypto.Hash
+	// KeyID is the fingerprint of the public key that made the signature
+	KeyID string
+	Value []byte
+}
+
+// SignDetached signs everything read from src into a detached signature
+func (r Rsa) SignDetached(src io.Reader, scheme Scheme, hash crypto.Hash) (*Signature, error) {
+	if r.Private == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	value, err := r.SignReader(src, scheme, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	keyID, err := PublicKeyFingerprint(&r.Private.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Signature{Scheme: scheme, Hash: hash, KeyID: keyID, Value: value}, nil
+}
+
+// VerifyDetached verifies a detached signature of everything read from src.
+// The signature must have been made by the public key.
+func (r Rsa) VerifyDetached(src io.Reader, sig *Signature) error {
+	if r.Public == nil {
+		return ErrNoPublicKey
+	}
+
+	keyID, err := PublicKeyFingerprint(r.Public)
+	if err != nil {
+		return err
+	}
+
+	if sig.KeyID != "" && sig.KeyID != keyID {
+		return fmt.Errorf("signed by key %s: %w", sig.KeyID, ErrInvalidSignature)
+	}
+
+	return r.VerifyReader(src, sig.Value, sig.Scheme, sig.Hash)
+}
+
+// Encode writes the signature as an "RSA SIGNATURE" PEM block whose headers
+// hold the scheme, hash and key id
+func (s Signature) Encode(w io.Writer) error {
+	block := &pem.Block{
+		Type: pemTypeSignature,
+		Headers: map[string]string{
+			signatureHeaderScheme: string(s.Scheme),
+			signatureHeaderHash:   s.Hash.String(),
+		},
+		Bytes: s.Value,
+	}
+
+	if s.KeyID != "" {
+		block.Headers[signatureHeaderKeyID] = s.KeyID
+	}
+
+	if err := pem.Encode(w, block); err != nil {
+		return fmt.Errorf("error when encode signature pem: %w", err)
+	}
+
+	return nil
+}
+
+// DecodeSignature reads a detached signature written by Signature.Encode
+func DecodeSignature(reader io.Reader) (*Signature, error) {
+	block, err := readPEM(reader, "signature")
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type != pemTypeSignature {
+		return nil, fmt.Errorf("unexpected PEM block %q: %w", block.Type, ErrFailedToParse)
+	}
+
+	hash, ok := supportedHashes[block.Headers[signatureHeaderHash]]
+	if !ok {
+		return nil, fmt.Errorf("hash %q: %w", block.Headers[signatureHeaderHash], ErrUnsupportedHash)
+	}
+
+	return &Signature{
+		Scheme: Scheme(block.Headers[signatureHeaderScheme]),
+		Hash:   hash,
+		KeyID:  block.Headers[signatureHeaderKeyID],
+		Value:  block.Bytes,
+	}, nil
+}
+
+// signDigest signs a digest with the private key
+func (r Rsa) signDigest(digest []byte, scheme Scheme, hash crypto.Hash) ([]byte, error) {
+	if r.Private == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	var sig []byte
+	var err error
+	switch scheme {
+	case SchemePSS:
+		sig, err = rsa.SignPSS(rand.Reader, r.Private, hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	case SchemePKCS1v15:
+		sig, err = rsa.SignPKCS1v15(nil, r.Private, hash, digest)
+	default:
+		return nil, fmt.Errorf("scheme %q: %w", scheme, ErrUnsupportedScheme)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+
+	return sig, nil
+}
+
+// verifyDigest verifies the signature of a digest with the public key
+func (r Rsa) verifyDigest(digest, sig []byte, scheme Scheme, hash crypto.Hash) error {
+	if r.Public == nil {
+		return ErrNoPublicKey
+	}
+
+	var err error
+	switch scheme {
+	case SchemePSS:
+		err = rsa.VerifyPSS(r.Public, hash, digest, sig, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+	case SchemePKCS1v15:
+		err = rsa.VerifyPKCS1v15(r.Public, hash, digest, sig)
+	default:
+		return fmt.Errorf("scheme %q: %w", scheme, ErrUnsupportedScheme)
+	}
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// digest hashes msg with a supported hash function
+func digest(hash crypto.Hash, msg []byte) ([]byte, error) {
+	if _, ok := supportedHashes[hash.String()]; !ok || !hash.Available() {
+		return nil, fmt.Errorf("hash %s: %w", hash, ErrUnsupportedHash)
+	}
+
+	h := hash.New()
+	h.Write(msg)
+
+	return h.Sum(nil), nil
+}
+
+// digestReader hashes everything read from src with a supported hash function
+func digestReader(hash crypto.Hash, src io.Reader) ([]byte, error) {
+	if _, ok := supportedHashes[hash.String()]; !ok || !hash.Available() {
+		return nil, fmt.Errorf("hash %s: %w", hash, ErrUnsupportedHash)
+	}
+
+	h := hash.New()
+	if _, err := io.Copy(h, src); err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+
+	return h.Sum(nil), nil
+}
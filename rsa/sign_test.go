@@ -0,0 +1,92 @@
+package rsa
+
+import (
+	"bytes"
+	"crypto"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRsa_SignVerify(t *testing.T) {
+	r := New()
+	if err := r.GenerateWithOpts(WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+
+	msg := []byte("release v1.2.3")
+
+	tests := []struct {
+		name    string
+		scheme  Scheme
+		hash    crypto.Hash
+		wantErr error
+	}{
+		{name: "PSS SHA-256", scheme: SchemePSS, hash: crypto.SHA256},
+		{name: "PSS SHA-512", scheme: SchemePSS, hash: crypto.SHA512},
+		{name: "PKCS1v15 SHA-384", scheme: SchemePKCS1v15, hash: crypto.SHA384},
+		{name: "Unsupported hash", scheme: SchemePSS, hash: crypto.MD5, wantErr: ErrUnsupportedHash},
+		{name: "Unsupported scheme", scheme: Scheme("X9.31"), hash: crypto.SHA256, wantErr: ErrUnsupportedScheme},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig, err := r.Sign(msg, tt.scheme, tt.hash)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Sign() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+
+			if err := r.Verify(msg, sig, tt.scheme, tt.hash); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+			if err := r.VerifyReader(bytes.NewReader(msg), sig, tt.scheme, tt.hash); err != nil {
+				t.Errorf("VerifyReader() error = %v", err)
+			}
+			if err := r.Verify([]byte("other"), sig, tt.scheme, tt.hash); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify(other) error = %v, want %v", err, ErrInvalidSignature)
+			}
+		})
+	}
+}
+
+func TestSignature_EncodeDecode(t *testing.T) {
+	r := New()
+	if err := r.GenerateWithOpts(WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+
+	artifact := strings.Repeat("binary", 1000)
+
+	sig, err := r.SignDetached(strings.NewReader(artifact), SchemePSS, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("SignDetached() error = %v", err)
+	}
+
+	var b bytes.Buffer
+	if err := sig.Encode(&b); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	got, err := DecodeSignature(&b)
+	if err != nil {
+		t.Fatalf("DecodeSignature() error = %v", err)
+	}
+
+	if err := r.VerifyDetached(strings.NewReader(artifact), got); err != nil {
+		t.Errorf("VerifyDetached() error = %v", err)
+	}
+	if err := r.VerifyDetached(strings.NewReader(artifact+"x"), got); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifyDetached(modified) error = %v, want %v", err, ErrInvalidSignature)
+	}
+
+	other := New()
+	if err := other.GenerateWithOpts(WithKeySize(3072), WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+	if err := other.VerifyDetached(strings.NewReader(artifact), got); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("VerifyDetached(other key) error = %v, want %v", err, ErrInvalidSignature)
+	}
+}
@@ -0,0 +1,169 @@
+package rsa
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	// ErrWeakKey is returned when a key does not satisfy the policy
+	ErrWeakKey = errors.New("key does not satisfy policy")
+	// ErrKeyMismatch is returned when a public key does not belong to a private key
+	ErrKeyMismatch = errors.New("public key does not match private key")
+)
+
+// Policy describes the keys that are acceptable
+type Policy struct {
+	// MinBits is the minimum modulus size
+	MinBits int
+	// Exponents are the accepted public exponents. When empty any odd
+	// exponent of at least 3 is accepted.
+	Exponents []int
+}
+
+// DefaultPolicy returns a policy accepting keys of at least 2048 bits with the exponent 65537
+func DefaultPolicy() Policy {
+	return Policy{
+		MinBits:   MinKeySize,
+		Exponents: []int{publicExponent},
+	}
+}
+
+// CheckPublicKey checks the modulus size and public exponent of the key
+func (p Policy) CheckPublicKey(pub *rsa.PublicKey) error {
+	if pub == nil || pub.N == nil {
+		return ErrNoPublicKey
+	}
+
+	if bits := pub.N.BitLen(); bits < p.MinBits {
+		return fmt.Errorf("modulus is %d bits, want at least %d: %w", bits, p.MinBits, ErrWeakKey)
+	}
+
+	if pub.N.Bit(0) == 0 {
+		return fmt.Errorf("modulus is even: %w", ErrWeakKey)
+	}
+
+	if pub.E < 3 || pub.E%2 == 0 {
+		return fmt.Errorf("public exponent %d: %w", pub.E, ErrWeakKey)
+	}
+
+	if len(p.Exponents) > 0 && !slices.Contains(p.Exponents, pub.E) {
+		return fmt.Errorf("public exponent %d not in %v: %w", pub.E, p.Exponents, ErrWeakKey)
+	}
+
+	return nil
+}
+
+// CheckPrivateKey checks the public half of the key against the policy and
+// runs the consistency checks of rsa.PrivateKey.Validate
+func (p Policy) CheckPrivateKey(private *rsa.PrivateKey) error {
+	if private == nil {
+		return ErrNoPrivateKey
+	}
+
+	if err := p.CheckPublicKey(&private.PublicKey); err != nil {
+		return err
+	}
+
+	if err := private.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", err, ErrWeakKey)
+	}
+
+	return nil
+}
+
+// Validate checks the keys that are set against the policy and, when both are
+// set, that the public key belongs to the private key
+func (r Rsa) Validate(p Policy) error {
+	if r.Private == nil && r.Public == nil {
+		return ErrNoPrivateKey
+	}
+
+	if r.Private != nil {
+		if err := p.CheckPrivateKey(r.Private); err != nil {
+			return err
+		}
+	}
+
+	if r.Public != nil {
+		if err := p.CheckPublicKey(r.Public); err != nil {
+			return err
+		}
+	}
+
+	if r.Private != nil && r.Public != nil {
+		return KeysMatch(r.Private, r.Public)
+	}
+
+	return nil
+}
+
+// KeysMatch returns ErrKeyMismatch unless the public key belongs to the private key
+func KeysMatch(private *rsa.PrivateKey, pub *rsa.PublicKey) error {
+	if private == nil {
+		return ErrNoPrivateKey
+	}
+
+	if pub == nil {
+		return ErrNoPublicKey
+	}
+
+	if !private.PublicKey.Equal(pub) {
+		return ErrKeyMismatch
+	}
+
+	return nil
+}
+
+// readOptions holds the settings used by the key readers
+type readOptions struct {
+	policy     *Policy
+	passphrase []byte
+}
+
+// ReadOpt is a functional option for the key readers.
+type ReadOpt func(*readOptions)
+
+// WithPolicy rejects keys that do not satisfy the policy.
+func WithPolicy(p Policy) ReadOpt {
+	return func(o *readOptions) {
+		o.policy = &p
+	}
+}
+
+// WithPassphrase decrypts encrypted private keys with the passphrase.
+func WithPassphrase(passphrase []byte) ReadOpt {
+	return func(o *readOptions) {
+		o.passphrase = passphrase
+	}
+}
+
+// newReadOptions applies the reader options
+func newReadOptions(opts []ReadOpt) readOptions {
+	var o readOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// checkPrivateKey applies the policy, if any, to a private key
+func (o readOptions) checkPrivateKey(private *rsa.PrivateKey) error {
+	if o.policy == nil {
+		return nil
+	}
+
+	return o.policy.CheckPrivateKey(private)
+}
+
+// checkPublicKey applies the policy, if any, to a public key
+func (o readOptions) checkPublicKey(pub *rsa.PublicKey) error {
+	if o.policy == nil {
+		return nil
+	}
+
+	return o.policy.CheckPublicKey(pub)
+}
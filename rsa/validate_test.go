@@ -0,0 +1,83 @@
+package rsa
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestPolicy_CheckPublicKey(t *testing.T) {
+	small := New()
+	if err := small.GenerateWithOpts(WithKeySize(1024), WithMinKeySize(1024), WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+	good := New()
+	if err := good.GenerateWithOpts(WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+
+	evenExponent := *good.Public
+	evenExponent.E = 65536
+	oddExponent := *good.Public
+	oddExponent.E = 3
+	evenModulus := *good.Public
+	evenModulus.N = new(big.Int).SetBit(new(big.Int).Set(good.Public.N), 0, 0)
+
+	tests := []struct {
+		name    string
+		policy  Policy
+		key     *Rsa
+		wantErr error
+	}{
+		{name: "Strong key", policy: DefaultPolicy(), key: good},
+		{name: "1024 bit key", policy: DefaultPolicy(), key: small, wantErr: ErrWeakKey},
+		{name: "1024 bit key with lowered minimum", policy: Policy{MinBits: 1024}, key: small},
+		{name: "Even exponent", policy: Policy{MinBits: 2048}, key: &Rsa{Public: &evenExponent}, wantErr: ErrWeakKey},
+		{name: "Exponent not allowed", policy: DefaultPolicy(), key: &Rsa{Public: &oddExponent}, wantErr: ErrWeakKey},
+		{name: "Exponent without allow list", policy: Policy{MinBits: 2048}, key: &Rsa{Public: &oddExponent}},
+		{name: "Even modulus", policy: DefaultPolicy(), key: &Rsa{Public: &evenModulus}, wantErr: ErrWeakKey},
+		{name: "Mismatched pair", policy: DefaultPolicy(), key: &Rsa{Private: good.Private, Public: &oddExponent}, wantErr: ErrWeakKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.key.Validate(tt.policy); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	other := New()
+	if err := other.GenerateWithOpts(WithKeySize(3072), WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+	if err := KeysMatch(good.Private, other.Public); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("KeysMatch() error = %v, want %v", err, ErrKeyMismatch)
+	}
+}
+
+func TestRsa_ReadPrivateKeyWithPolicy(t *testing.T) {
+	small := New()
+	if err := small.GenerateWithOpts(WithKeySize(1024), WithMinKeySize(1024), WithKeyCache(testKeys)); err != nil {
+		t.Fatalf("GenerateWithOpts() error = %v", err)
+	}
+
+	var private, public bytes.Buffer
+	if err := small.WritePrivateKey(&private); err != nil {
+		t.Fatalf("WritePrivateKey() error = %v", err)
+	}
+	if err := small.WritePublicKey(&public); err != nil {
+		t.Fatalf("WritePublicKey() error = %v", err)
+	}
+
+	if err := New().ReadPrivateKey(bytes.NewReader(private.Bytes())); err != nil {
+		t.Errorf("ReadPrivateKey() error = %v", err)
+	}
+	if err := New().ReadPrivateKey(bytes.NewReader(private.Bytes()), WithPolicy(DefaultPolicy())); !errors.Is(err, ErrWeakKey) {
+		t.Errorf("ReadPrivateKey(WithPolicy) error = %v, want %v", err, ErrWeakKey)
+	}
+	if _, err := PublicKeyFromBytes(public.Bytes(), WithPolicy(DefaultPolicy())); !errors.Is(err, ErrWeakKey) {
+		t.Errorf("PublicKeyFromBytes(WithPolicy) error = %v, want %v", err, ErrWeakKey)
+	}
+}